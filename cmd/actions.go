@@ -0,0 +1,149 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/TheHipbot/hermes/pkg/storage"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var (
+	// flag vars
+	openFlg bool
+	webFlg  bool
+	tmuxFlg bool
+
+	// runAction runs a command attached to the terminal, it is
+	// a variable so it may be replaced in tests
+	runAction = func(name string, args ...string) error {
+		c := exec.Command(name, args...)
+		c.Stdin = os.Stdin
+		c.Stdout = os.Stdout
+		c.Stderr = os.Stderr
+		return c.Run()
+	}
+
+	// outputAction runs a command and returns its stdout, it is
+	// a variable so it may be replaced in tests
+	outputAction = func(name string, args ...string) ([]byte, error) {
+		return exec.Command(name, args...).Output()
+	}
+
+	errNoEditor  = errors.New("no editor configured, set editor in .hermes.yml or $EDITOR")
+	errNoBrowser = errors.New("no browser configured, set browser in .hermes.yml")
+)
+
+// addActionFlags adds the post-selection action flags to a command
+// which runs the getHandler
+func addActionFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolVarP(&openFlg, "open", "o", false, "open the repo in your editor")
+	cmd.Flags().BoolVarP(&webFlg, "web", "w", false, "open the repo's web page in your browser")
+	cmd.Flags().BoolVar(&tmuxFlg, "tmux", false, "open or switch to a tmux window named after the repo")
+}
+
+// actionEnabled returns the value of the flag if it was set on the
+// command, otherwise the default from the config key
+func actionEnabled(cmd *cobra.Command, flag, key string) bool {
+	if f := cmd.Flags().Lookup(flag); f != nil && f.Changed {
+		v, _ := cmd.Flags().GetBool(flag)
+		return v
+	}
+	return viper.GetBool(key)
+}
+
+// runPostSelectActions runs the actions enabled by flags or config
+// on the selected repo
+func runPostSelectActions(cmd *cobra.Command, r storage.Repository) error {
+	if actionEnabled(cmd, "open", "actions.open") {
+		if err := openInEditor(r); err != nil {
+			return fmt.Errorf("error opening repo in editor\n%s", err)
+		}
+	}
+	if actionEnabled(cmd, "web", "actions.web") {
+		if err := openInBrowser(r); err != nil {
+			return fmt.Errorf("error opening repo in browser\n%s", err)
+		}
+	}
+	if actionEnabled(cmd, "tmux", "actions.tmux") {
+		if err := openInTmux(r); err != nil {
+			return fmt.Errorf("error opening repo in tmux\n%s", err)
+		}
+	}
+	return nil
+}
+
+func openInEditor(r storage.Repository) error {
+	editor := viper.GetString("editor")
+	if editor == "" {
+		editor = os.Getenv("VISUAL")
+	}
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	parts := strings.Fields(editor)
+	if len(parts) == 0 {
+		return errNoEditor
+	}
+	return runAction(parts[0], append(parts[1:], r.Path)...)
+}
+
+func openInBrowser(r storage.Repository) error {
+	webURL := r.WebURL
+	if webURL == "" {
+		webURL = fmt.Sprintf("https://%s", r.Name)
+	}
+
+	browser := viper.GetString("browser")
+	if browser == "" {
+		switch runtime.GOOS {
+		case "darwin":
+			browser = "open"
+		case "windows":
+			browser = "rundll32 url.dll,FileProtocolHandler"
+		default:
+			browser = "xdg-open"
+		}
+	}
+	parts := strings.Fields(browser)
+	if len(parts) == 0 {
+		return errNoBrowser
+	}
+	return runAction(parts[0], append(parts[1:], webURL)...)
+}
+
+// tmuxName returns the name of the tmux window or session for the repo,
+// tmux does not allow . or : in target names
+func tmuxName(r storage.Repository) string {
+	name := r.Name[strings.LastIndex(r.Name, "/")+1:]
+	return strings.NewReplacer(".", "_", ":", "_").Replace(name)
+}
+
+// openInTmux switches to the window named after the repo in the current
+// tmux session, creating it if needed. Outside of tmux it attaches to
+// or creates a session named after the repo
+func openInTmux(r storage.Repository) error {
+	name := tmuxName(r)
+	if os.Getenv("TMUX") == "" {
+		return runAction("tmux", "new-session", "-A", "-s", name, "-c", r.Path)
+	}
+
+	out, err := outputAction("tmux", "list-windows", "-F", "#{window_name}")
+	if err != nil {
+		return err
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		if scanner.Text() == name {
+			return runAction("tmux", "select-window", "-t", fmt.Sprintf("=%s", name))
+		}
+	}
+	return runAction("tmux", "new-window", "-n", name, "-c", r.Path)
+}
@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"os"
+	"testing"
+
+	"github.com/TheHipbot/hermes/pkg/storage"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/suite"
+)
+
+type ActionsSuite struct {
+	suite.Suite
+	calls        [][]string
+	tmux         string
+	runAction    func(name string, args ...string) error
+	outputAction func(name string, args ...string) ([]byte, error)
+}
+
+var testActionRepo = storage.Repository{
+	Name:   "github.com/TheHipbot/hermes.go",
+	Path:   "/repos/github.com/TheHipbot/hermes.go",
+	WebURL: "https://github.com/TheHipbot/hermes.go",
+}
+
+func (s *ActionsSuite) SetupSuite() {
+	s.runAction = runAction
+	s.outputAction = outputAction
+}
+
+func (s *ActionsSuite) TearDownSuite() {
+	runAction = s.runAction
+	outputAction = s.outputAction
+}
+
+func (s *ActionsSuite) SetupTest() {
+	s.calls = [][]string{}
+	s.tmux = os.Getenv("TMUX")
+	runAction = func(name string, args ...string) error {
+		s.calls = append(s.calls, append([]string{name}, args...))
+		return nil
+	}
+	outputAction = func(name string, args ...string) ([]byte, error) {
+		s.calls = append(s.calls, append([]string{name}, args...))
+		return []byte("zsh\nhermes_go\n"), nil
+	}
+	viper.Set("editor", "")
+	viper.Set("browser", "")
+	viper.Set("actions.open", false)
+	viper.Set("actions.web", false)
+	viper.Set("actions.tmux", false)
+}
+
+func (s *ActionsSuite) TearDownTest() {
+	os.Setenv("TMUX", s.tmux)
+	viper.Set("editor", "")
+	viper.Set("browser", "")
+	viper.Set("actions.open", false)
+	viper.Set("actions.web", false)
+	viper.Set("actions.tmux", false)
+}
+
+func (s *ActionsSuite) TestOpenInEditor() {
+	viper.Set("editor", "code -n")
+	s.Nil(openInEditor(testActionRepo))
+	s.Equal([][]string{{"code", "-n", testActionRepo.Path}}, s.calls, "Editor should be run with its args and the repo path")
+}
+
+func (s *ActionsSuite) TestOpenInEditorNoEditor() {
+	editor, visual := os.Getenv("EDITOR"), os.Getenv("VISUAL")
+	defer os.Setenv("EDITOR", editor)
+	defer os.Setenv("VISUAL", visual)
+	os.Setenv("EDITOR", "")
+	os.Setenv("VISUAL", "")
+	s.Equal(errNoEditor, openInEditor(testActionRepo))
+}
+
+func (s *ActionsSuite) TestOpenInBrowser() {
+	viper.Set("browser", "firefox")
+	s.Nil(openInBrowser(testActionRepo))
+	s.Nil(openInBrowser(storage.Repository{Name: "gitlab.com/TheHipbot/hermes"}))
+	s.Equal([][]string{
+		{"firefox", "https://github.com/TheHipbot/hermes.go"},
+		{"firefox", "https://gitlab.com/TheHipbot/hermes"},
+	}, s.calls, "Browser should be run with the web url or one made from the name")
+}
+
+func (s *ActionsSuite) TestOpenInTmuxOutsideTmux() {
+	os.Setenv("TMUX", "")
+	s.Nil(openInTmux(testActionRepo))
+	s.Equal([][]string{{"tmux", "new-session", "-A", "-s", "hermes_go", "-c", testActionRepo.Path}}, s.calls)
+}
+
+func (s *ActionsSuite) TestOpenInTmuxExistingWindow() {
+	os.Setenv("TMUX", "/tmp/tmux-1000/default,1,0")
+	s.Nil(openInTmux(testActionRepo))
+	s.Equal([]string{"tmux", "select-window", "-t", "=hermes_go"}, s.calls[1], "Existing window should be selected")
+}
+
+func (s *ActionsSuite) TestOpenInTmuxNewWindow() {
+	os.Setenv("TMUX", "/tmp/tmux-1000/default,1,0")
+	r := testActionRepo
+	r.Name = "github.com/TheHipbot/dotfiles"
+	s.Nil(openInTmux(r))
+	s.Equal([]string{"tmux", "new-window", "-n", "dotfiles", "-c", r.Path}, s.calls[1], "Missing window should be created")
+}
+
+func (s *ActionsSuite) TestRunPostSelectActionsDefaults() {
+	c := &cobra.Command{}
+	addActionFlags(c)
+	viper.Set("browser", "firefox")
+	viper.Set("actions.web", true)
+	s.Nil(runPostSelectActions(c, testActionRepo))
+	s.Equal([][]string{{"firefox", testActionRepo.WebURL}}, s.calls, "Actions enabled in config should run")
+
+	s.calls = [][]string{}
+	c.Flags().Set("web", "false")
+	s.Nil(runPostSelectActions(c, testActionRepo))
+	s.Empty(s.calls, "Flags should override actions enabled in config")
+}
+
+func TestActionsSuite(t *testing.T) {
+	suite.Run(t, new(ActionsSuite))
+}
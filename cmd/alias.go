@@ -2,20 +2,120 @@ package cmd
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/alecthomas/template"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
 
+var (
+	aliasShellFlg = ""
+
+	errInvalidShell = fmt.Errorf("invalid shell, valid values are %s", aliasShells)
+
+	aliasShells = []string{
+		"bash",
+		"zsh",
+		"fish",
+		"nushell",
+	}
+
+	aliasTemplates = map[string]string{
+		"bash": `function {{ .AliasName }}() {
+	local HERMES_BIN={{ quote .Bin }}
+	if [ ! -x "$HERMES_BIN" ]; then
+		HERMES_BIN="$(type -P hermes)"
+	fi
+	local HERMES_TARGET
+	HERMES_TARGET="$(mktemp "${TMPDIR:-/tmp}/hermes_target.XXXXXX")" || return 1
+	HERMES_TARGET_FILE="$HERMES_TARGET" "$HERMES_BIN" "$@"
+	local EXIT_STATUS=$?
+	if [ -s "$HERMES_TARGET" ]; then
+		cd -- "$(cat "$HERMES_TARGET")"
+	fi
+	rm -f -- "$HERMES_TARGET"
+	return $EXIT_STATUS
+}
+if type __start_hermes > /dev/null 2>&1; then
+	complete -o default -F __start_hermes {{ .AliasName }}
+fi
+`,
+		"zsh": `function {{ .AliasName }}() {
+	local HERMES_BIN={{ quote .Bin }}
+	if [[ ! -x "$HERMES_BIN" ]]; then
+		HERMES_BIN="$(whence -p hermes)"
+	fi
+	local HERMES_TARGET
+	HERMES_TARGET="$(mktemp "${TMPDIR:-/tmp}/hermes_target.XXXXXX")" || return 1
+	HERMES_TARGET_FILE="$HERMES_TARGET" "$HERMES_BIN" "$@"
+	local EXIT_STATUS=$?
+	if [[ -s "$HERMES_TARGET" ]]; then
+		cd -- "$(<"$HERMES_TARGET")"
+	fi
+	rm -f -- "$HERMES_TARGET"
+	return $EXIT_STATUS
+}
+if (( $+functions[compdef] )) && (( $+functions[_hermes] )); then
+	compdef _hermes {{ .AliasName }}
+fi
+`,
+		"fish": `function {{ .AliasName }}{{ if ne .AliasName "hermes" }} --wraps hermes{{ end }}
+	set -l hermes_bin {{ quote .Bin }}
+	if not test -x $hermes_bin
+		set hermes_bin (command -s hermes)
+	end
+	set -l tmp_dir /tmp
+	set -q TMPDIR; and set tmp_dir $TMPDIR
+	set -l hermes_target (mktemp "$tmp_dir/hermes_target.XXXXXX"); or return 1
+	env HERMES_TARGET_FILE=$hermes_target $hermes_bin $argv
+	set -l exit_status $status
+	if test -s $hermes_target
+		cd (cat $hermes_target)
+	end
+	rm -f $hermes_target
+	return $exit_status
+end
+`,
+		"nushell": `def --env --wrapped {{ .AliasName }} [...args] {
+	let hermes_bin = if ({{ quote .Bin }} | path exists) { {{ quote .Bin }} } else { (which -a hermes | where type == external | get 0.path) }
+	let hermes_target = (mktemp -t hermes_target.XXXXXX)
+	with-env { HERMES_TARGET_FILE: $hermes_target } { ^$hermes_bin ...$args }
+	let target = (open --raw $hermes_target)
+	rm $hermes_target
+	if ($target | is-not-empty) {
+		cd $target
+	}
+}
+`,
+	}
+
+	aliasQuoters = map[string]func(string) string{
+		"bash":    quotePOSIX,
+		"zsh":     quotePOSIX,
+		"fish":    quoteFish,
+		"nushell": quoteNushell,
+	}
+)
+
+func init() {
+	aliasCmd.Flags().StringVarP(&aliasShellFlg, "shell", "s", "", "shell to output the alias for (default is detected from $SHELL)")
+}
+
 // aliasCmd represents the base command when called without any subcommands
 var aliasCmd = &cobra.Command{
 	Use:   "alias",
 	Short: "Outputs shell function for hermes alias",
 	Run: func(cmd *cobra.Command, args []string) {
-		alias, err := generateAlias()
+		shell := aliasShellFlg
+		if shell == "" {
+			shell = detectShell(os.Getenv("SHELL"))
+		}
+		alias, err := generateAlias(shell)
 		if err != nil {
 			fmt.Printf("Error generating alias\n%s", err)
 			os.Exit(1)
@@ -28,30 +128,61 @@ var aliasCmd = &cobra.Command{
 // AliasData is a struct containing the data
 // for the alias function template
 type AliasData struct {
+	Bin            string
 	ConfigDir      string
 	TargetFileName string
 	AliasName      string
 }
 
-func generateAlias() (string, error) {
+// detectShell returns the alias shell matching the given shell path,
+// falling back to bash for unknown shells
+func detectShell(shellPath string) string {
+	switch filepath.Base(shellPath) {
+	case "zsh":
+		return "zsh"
+	case "fish":
+		return "fish"
+	case "nu", "nushell":
+		return "nushell"
+	}
+	return "bash"
+}
+
+// hermesBin returns the path the running hermes binary was
+// installed at
+func hermesBin() (string, error) {
+	bin, err := os.Executable()
+	if err != nil {
+		return "", err
+	}
+	if resolved, err := filepath.EvalSymlinks(bin); err == nil {
+		bin = resolved
+	}
+	return bin, nil
+}
+
+func generateAlias(shell string) (string, error) {
+	aliasTemplate, ok := aliasTemplates[shell]
+	if !ok {
+		return "", errInvalidShell
+	}
+
+	bin, err := hermesBin()
+	if err != nil {
+		return "", errors.New("could not resolve hermes binary path")
+	}
+
 	var resolved bytes.Buffer
 	data := AliasData{
+		Bin:            bin,
 		ConfigDir:      viper.GetString("config_path"),
 		TargetFileName: viper.GetString("target_file"),
 		AliasName:      viper.GetString("alias_name"),
 	}
 
-	aliasTemplate := `function {{ .AliasName }}() {
-	local HERMES_BIN=$(whereis hermes | awk -F: '{gsub(/ /, "", $2); print $2}' || which hermes)
-	$HERMES_BIN $@
-	local EXIT_STATUS=$?
-	if [ -f {{ .ConfigDir }}{{ .TargetFileName }} ]; then
-		cd $(cat {{ .ConfigDir }}{{ .TargetFileName }}) && rm {{ .ConfigDir }}{{ .TargetFileName }}
-	fi
-	return $EXIT_STATUS
-}`
-
-	t, err := template.New("alias").Parse(aliasTemplate)
+	t, err := template.New("alias").Funcs(template.FuncMap{
+		"quote": aliasQuoters[shell],
+	}).Parse(aliasTemplate)
 	if err != nil {
 		return "", err
 	}
@@ -62,3 +193,20 @@ func generateAlias() (string, error) {
 
 	return resolved.String(), nil
 }
+
+// quotePOSIX single quotes a string for bash and zsh
+func quotePOSIX(s string) string {
+	return "'" + strings.Replace(s, "'", `'\''`, -1) + "'"
+}
+
+// quoteFish single quotes a string for fish
+func quoteFish(s string) string {
+	s = strings.Replace(s, `\`, `\\`, -1)
+	return "'" + strings.Replace(s, "'", `\'`, -1) + "'"
+}
+
+// quoteNushell double quotes a string for nushell
+func quoteNushell(s string) string {
+	s = strings.Replace(s, `\`, `\\`, -1)
+	return `"` + strings.Replace(s, `"`, `\"`, -1) + `"`
+}
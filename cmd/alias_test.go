@@ -23,13 +23,17 @@ func init() {
 func TestGenerateAliasDefault(t *testing.T) {
 	assert := assert.New(t)
 
-	alias, err := generateAlias()
+	viper.Set("alias_name", "hermes")
+	alias, err := generateAlias("bash")
 	assert.Nil(err, "generateAlias should not return an error")
 	assert.Contains(alias, fmt.Sprintf("function %s()", "hermes"))
-	assert.Contains(alias, `$HERMES_BIN $@
+	assert.Contains(alias, `HERMES_TARGET_FILE="$HERMES_TARGET" "$HERMES_BIN" "$@"
 	local EXIT_STATUS=$?`, "alias should capture the exit code from the hermes binaray")
 	assert.Contains(alias, "return $EXIT_STATUS", "alias should return exit status from binary")
-	assert.Contains(alias, fmt.Sprintf("%s%s", testConfigPath, testTargetFile), "generateAlias should have the correct target path")
+	assert.Contains(alias, `mktemp "${TMPDIR:-/tmp}/hermes_target.XXXXXX"`, "alias should create a target file per invocation")
+	assert.Contains(alias, `if [ -s "$HERMES_TARGET" ]; then
+		cd -- "$(cat "$HERMES_TARGET")"`, "alias should only move to targets written by this invocation")
+	assert.NotContains(alias, fmt.Sprintf("%s%s", testConfigPath, testTargetFile), "alias should not use the shared target file")
 }
 
 func TestGenerateAliasWithName(t *testing.T) {
@@ -37,7 +41,55 @@ func TestGenerateAliasWithName(t *testing.T) {
 
 	testAliasName := "testAlias"
 	viper.Set("alias_name", testAliasName)
-	alias, err := generateAlias()
+	defer viper.Set("alias_name", "hermes")
+	alias, err := generateAlias("bash")
 	assert.Nil(err, "generateAlias should not return an error")
 	assert.Contains(alias, fmt.Sprintf("function %s()", testAliasName))
+	assert.Contains(alias, fmt.Sprintf("complete -o default -F __start_hermes %s", testAliasName), "alias should wire bash completion to the alias function")
+
+	alias, err = generateAlias("zsh")
+	assert.Nil(err, "generateAlias should not return an error")
+	assert.Contains(alias, fmt.Sprintf("compdef _hermes %s", testAliasName), "alias should wire zsh completion to the alias function")
+
+	alias, err = generateAlias("fish")
+	assert.Nil(err, "generateAlias should not return an error")
+	assert.Contains(alias, fmt.Sprintf("function %s --wraps hermes", testAliasName), "alias should wrap hermes completion in fish")
+}
+
+func TestGenerateAliasShells(t *testing.T) {
+	assert := assert.New(t)
+
+	viper.Set("alias_name", "hermes")
+	expected := map[string]string{
+		"bash":    "function hermes()",
+		"zsh":     "function hermes()",
+		"fish":    "function hermes\n",
+		"nushell": "def --env --wrapped hermes [...args]",
+	}
+	for shell, contains := range expected {
+		alias, err := generateAlias(shell)
+		assert.Nil(err, "generateAlias should not return an error")
+		assert.Contains(alias, contains, fmt.Sprintf("%s alias should define the alias function", shell))
+	}
+
+	_, err := generateAlias("csh")
+	assert.Equal(errInvalidShell, err, "unknown shells should return an error")
+}
+
+func TestDetectShell(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal("bash", detectShell("/bin/bash"))
+	assert.Equal("zsh", detectShell("/usr/local/bin/zsh"))
+	assert.Equal("fish", detectShell("/usr/bin/fish"))
+	assert.Equal("nushell", detectShell("/home/user/.cargo/bin/nu"))
+	assert.Equal("bash", detectShell(""), "bash should be the default shell")
+}
+
+func TestAliasQuoting(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal(`'/my repos/it'\''s'`, quotePOSIX("/my repos/it's"))
+	assert.Equal(`'/my repos/it\'s'`, quoteFish("/my repos/it's"))
+	assert.Equal(`"/my repos/\"x\""`, quoteNushell(`/my repos/"x"`))
 }
@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/TheHipbot/hermes/pkg/storage"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var (
+	cacheBackends = []string{
+		"json",
+		"bolt",
+	}
+
+	errInvalidCacheBackend = errors.New("cache backend must be one of json or bolt")
+)
+
+func init() {
+	cacheCmd.AddCommand(cacheMigrateCmd)
+
+	cacheMigrateCmd.Flags().String("to", "", "backend to move the cache to (json or bolt)")
+	cacheMigrateCmd.MarkFlagRequired("to")
+	cacheMigrateCmd.RegisterFlagCompletionFunc("to", completeCacheBackends)
+}
+
+// cacheCmd represents the base cache command when called without any subcommands
+var cacheCmd = &cobra.Command{
+	Use:   "cache [subcommand]",
+	Short: "Manage the hermes cache",
+	Run: func(cmd *cobra.Command, args []string) {
+		cmd.Help()
+		os.Exit(0)
+	},
+}
+
+var cacheMigrateCmd = &cobra.Command{
+	Use:   "migrate --to [json|bolt]",
+	Short: "Move the cache to another storage backend",
+	Long: `Migrate copies every remote and repo from the cache backend set by
+cache_backend into the given backend. Once done set cache_backend in
+your config to start using the new cache, the old cache is left in place.`,
+	Run: cacheMigrateHandler,
+}
+
+// newStore creates the cache for the given backend
+func newStore(backend string) (storage.Storage, error) {
+	switch backend {
+	case "", "json":
+		return storage.NewFileStorage(configFS.FS, configFS.CachePath()), nil
+	case "bolt":
+		return storage.NewBoltStorage(configFS.CacheDBPath()), nil
+	}
+	return nil, errInvalidCacheBackend
+}
+
+func cacheMigrateHandler(cmd *cobra.Command, args []string) {
+	to, _ := cmd.Flags().GetString("to")
+	from := viper.GetString("cache_backend")
+	if from == "" {
+		from = "json"
+	}
+	if from == to {
+		fmt.Printf("cache is already using the %s backend\n", to)
+		os.Exit(ExitInvalidArguments)
+	}
+
+	dst, err := newStore(to)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(ExitInvalidArguments)
+	}
+
+	openStore()
+	defer store.Close()
+	if err := dst.Open(); err != nil {
+		fmt.Printf("Error opening %s cache\n%s\n", to, err)
+		os.Exit(1)
+	}
+	defer dst.Close()
+
+	if err := storage.Copy(dst, store); err != nil {
+		fmt.Printf("Error migrating cache to %s\n%s\n", to, err)
+		os.Exit(1)
+	}
+	fmt.Printf("cache migrated to %s, set cache_backend: %s in your config to use it\n", to, to)
+}
+
+func completeCacheBackends(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return cacheBackends, cobra.ShellCompDirectiveNoFileComp
+}
@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/TheHipbot/hermes/pkg/repo"
+	"github.com/TheHipbot/hermes/pkg/storage"
+	"github.com/spf13/cobra"
+)
+
+var errNoCloneSelection = errors.New("give a search, --remote, --tag or --all to choose the repos to clone")
+
+func init() {
+	cloneCmd.Flags().Bool("all", false, "clone every repo in the cache")
+	cloneCmd.Flags().String("remote", "", "only clone repos from the remote")
+	cloneCmd.Flags().StringSlice("tag", []string{}, "only clone repos with the tag")
+	cloneCmd.Flags().IntP("jobs", "j", 4, "number of repos to clone at once")
+	addCloneFlags(cloneCmd)
+	cloneCmd.RegisterFlagCompletionFunc("remote", completeRemoteNames)
+	cloneCmd.RegisterFlagCompletionFunc("tag", completeTags)
+}
+
+var cloneCmd = &cobra.Command{
+	Use:   "clone [search]",
+	Short: "Clone every cached repo matching the search which is not already cloned",
+	Long: `Clone finds every repo in the cache matching the search, --remote
+and --tag (or every repo with --all), and clones those which are not
+already in the repo_path, several at a time. Failed clones do not stop
+the others, they are reported at the end.`,
+	ValidArgsFunction: completeRepoNames,
+	Run:               cloneHandler,
+}
+
+func cloneHandler(cmd *cobra.Command, args []string) {
+	all, _ := cmd.Flags().GetBool("all")
+	remoteName, _ := cmd.Flags().GetString("remote")
+	tags, _ := cmd.Flags().GetStringSlice("tag")
+	jobs, _ := cmd.Flags().GetInt("jobs")
+
+	query := args
+	if remoteName != "" {
+		query = append(query, fmt.Sprintf("remote:%s", remoteName))
+	}
+	for _, t := range tags {
+		query = append(query, fmt.Sprintf("tag:%s", t))
+	}
+	if len(query) == 0 && !all {
+		fmt.Println(errNoCloneSelection)
+		os.Exit(ExitInvalidArguments)
+	}
+
+	openStore()
+	matches := store.SearchRepositories(strings.Join(query, " "))
+	toClone := []storage.Repository{}
+	for _, r := range matches {
+		if _, err := appFs.Stat(r.Path); err != nil {
+			toClone = append(toClone, r)
+		}
+	}
+
+	results := cloneRepos(cmd, toClone, jobs)
+	failed := printFailures(os.Stderr, results)
+	fmt.Fprintf(os.Stderr, "%d cloned, %d already cloned, %d failed\n", len(results)-failed, len(matches)-len(toClone), failed)
+	if failed > 0 {
+		os.Exit(1)
+	}
+}
+
+// cloneRepos clones the repos with up to jobs at once, printing a line
+// as each finishes. Repos which are not in the cache are added to it
+// once cloned. The store must be open when it is called, it is closed
+// while the repos clone, so other hermes processes are not kept waiting
+// on its lock, and is left closed once the new repos are saved
+func cloneRepos(cmd *cobra.Command, repos []storage.Repository, jobs int) []repoResult {
+	// the store is not safe to use from the workers, so every
+	// repo is resolved before any are cloned
+	gitRepos := map[string]*repo.GitRepository{}
+	progress := newCloneProgress(cmd)
+	for _, r := range repos {
+		gitRepos[r.Name] = gitRepository(r, remoteProtocol(r.Name))
+		gitRepos[r.Name].CloneOptions = cloneOptions(cmd, r)
+		gitRepos[r.Name].CloneOptions.GitConfig = remoteGitConfig(r.Name)
+		gitRepos[r.Name].CloneOptions.Progress = progress.reporter(r.Name)
+	}
+	store.Close()
+
+	results := runOnRepos(progress.writer(os.Stderr), repos, jobs, "cloned", func(r storage.Repository) error {
+		err := gitRepos[r.Name].Clone(r.Path)
+		if err == repo.ErrRepoAlreadyExists {
+			return nil
+		}
+		return err
+	})
+	progress.clear()
+
+	openStore()
+	defer store.Close()
+	for _, res := range results {
+		if res.Err != nil {
+			continue
+		}
+		if _, ok := store.GetRepository(res.Repo.Name); !ok {
+			repoToAdd := res.Repo
+			store.AddRepository(&repoToAdd)
+		}
+	}
+	saveStore()
+	return results
+}
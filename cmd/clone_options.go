@@ -0,0 +1,149 @@
+package cmd
+
+import (
+	"strings"
+
+	"github.com/TheHipbot/hermes/pkg/repo"
+	"github.com/TheHipbot/hermes/pkg/storage"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// cloneSettings are options for cloning repos which may be set in the
+// clone section of the config, for every repo or for a remote or repo
+// by name. Unset options are nil so they do not override others
+type cloneSettings struct {
+	Name           string   `mapstructure:"name"`
+	Depth          *int     `mapstructure:"depth"`
+	SingleBranch   *bool    `mapstructure:"single_branch"`
+	Branch         *string  `mapstructure:"branch"`
+	Filter         *string  `mapstructure:"filter"`
+	Sparse         []string `mapstructure:"sparse"`
+	Submodules     *bool    `mapstructure:"submodules"`
+	SubmoduleDepth *int     `mapstructure:"submodule_depth"`
+	SubmoduleJobs  *int     `mapstructure:"submodule_jobs"`
+	LFS            *bool    `mapstructure:"lfs"`
+}
+
+// cloneConfig is the clone section of the config, remote names are
+// given in lists as viper splits map keys containing dots
+type cloneConfig struct {
+	cloneSettings `mapstructure:",squash"`
+	Remotes       []cloneSettings `mapstructure:"remotes"`
+	Repos         []cloneSettings `mapstructure:"repos"`
+}
+
+// addCloneFlags adds the flags which change how repos are cloned
+func addCloneFlags(cmd *cobra.Command) {
+	cmd.Flags().Int("depth", 0, "clone only the given number of commits")
+	cmd.Flags().Bool("single-branch", false, "only fetch the branch which is checked out")
+	cmd.Flags().String("branch", "", "check out the branch or tag instead of the default branch")
+	cmd.Flags().String("filter", "", "partial clone filter, such as blob:none")
+	cmd.Flags().StringSlice("sparse", []string{}, "only check out the given directories")
+	cmd.Flags().Bool("recurse-submodules", false, "clone submodules, and theirs")
+	cmd.Flags().Int("submodule-depth", 0, "levels of nested submodules to clone, all when 0")
+	cmd.Flags().Int("submodule-jobs", 0, "number of submodules to fetch at once")
+	cmd.Flags().Bool("lfs", true, "fetch Git LFS files of repos which use LFS")
+	cmd.Flags().Bool("json", false, "print clone progress as json lines on stderr")
+}
+
+// cloneOptions returns the options to clone the repo with, flags set on
+// the command override the config for the repo, then for its remote,
+// then for every repo. LFS files are fetched unless turned off
+func cloneOptions(cmd *cobra.Command, r storage.Repository) repo.CloneOptions {
+	cfg := cloneConfig{}
+	viper.UnmarshalKey("clone", &cfg)
+
+	layers := []cloneSettings{cfg.cloneSettings}
+	remoteName := strings.Split(r.Name, "/")[0]
+	for _, s := range cfg.Remotes {
+		if s.Name == remoteName {
+			layers = append(layers, s)
+		}
+	}
+	for _, s := range cfg.Repos {
+		if s.Name == r.Name {
+			layers = append(layers, s)
+		}
+	}
+	layers = append(layers, cloneFlagSettings(cmd))
+
+	opts := repo.CloneOptions{
+		LFS: true,
+	}
+	for _, s := range layers {
+		if s.Depth != nil {
+			opts.Depth = *s.Depth
+		}
+		if s.SingleBranch != nil {
+			opts.SingleBranch = *s.SingleBranch
+		}
+		if s.Branch != nil {
+			opts.Reference = *s.Branch
+		}
+		if s.Filter != nil {
+			opts.Filter = *s.Filter
+		}
+		if s.Sparse != nil {
+			opts.SparsePaths = s.Sparse
+		}
+		if s.Submodules != nil {
+			opts.Submodules = *s.Submodules
+		}
+		if s.SubmoduleDepth != nil {
+			opts.SubmoduleDepth = *s.SubmoduleDepth
+		}
+		if s.SubmoduleJobs != nil {
+			opts.SubmoduleJobs = *s.SubmoduleJobs
+		}
+		if s.LFS != nil {
+			opts.LFS = *s.LFS
+		}
+	}
+	return opts
+}
+
+// cloneFlagSettings returns the clone flags which were set on the command
+func cloneFlagSettings(cmd *cobra.Command) cloneSettings {
+	s := cloneSettings{}
+	changed := func(name string) bool {
+		f := cmd.Flags().Lookup(name)
+		return f != nil && f.Changed
+	}
+	if changed("depth") {
+		depth, _ := cmd.Flags().GetInt("depth")
+		s.Depth = &depth
+	}
+	if changed("single-branch") {
+		single, _ := cmd.Flags().GetBool("single-branch")
+		s.SingleBranch = &single
+	}
+	if changed("branch") {
+		branch, _ := cmd.Flags().GetString("branch")
+		s.Branch = &branch
+	}
+	if changed("filter") {
+		filter, _ := cmd.Flags().GetString("filter")
+		s.Filter = &filter
+	}
+	if changed("sparse") {
+		s.Sparse, _ = cmd.Flags().GetStringSlice("sparse")
+	}
+	if changed("recurse-submodules") {
+		submodules, _ := cmd.Flags().GetBool("recurse-submodules")
+		s.Submodules = &submodules
+	}
+	if changed("submodule-depth") {
+		depth, _ := cmd.Flags().GetInt("submodule-depth")
+		s.SubmoduleDepth = &depth
+	}
+	if changed("submodule-jobs") {
+		jobs, _ := cmd.Flags().GetInt("submodule-jobs")
+		s.SubmoduleJobs = &jobs
+	}
+	if changed("lfs") {
+		lfs, _ := cmd.Flags().GetBool("lfs")
+		s.LFS = &lfs
+	}
+	return s
+}
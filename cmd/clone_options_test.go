@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/TheHipbot/hermes/pkg/repo"
+	"github.com/TheHipbot/hermes/pkg/storage"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/suite"
+)
+
+type CloneOptionsSuite struct {
+	suite.Suite
+}
+
+func (suite *CloneOptionsSuite) SetupTest() {
+	viper.SetConfigType("yaml")
+	suite.Nil(viper.ReadConfig(bytes.NewBufferString(`
+clone:
+  depth: 1
+  submodule_jobs: 4
+  remotes:
+  - name: github.com
+    single_branch: true
+  - name: gitlab.com
+    submodules: true
+    submodule_depth: 1
+  repos:
+  - name: github.com/payments/monorepo
+    depth: 0
+    filter: blob:none
+    sparse:
+    - services/payments
+    lfs: false
+`)))
+}
+
+func (suite *CloneOptionsSuite) TearDownTest() {
+	viper.ReadConfig(bytes.NewBufferString(""))
+}
+
+func (suite *CloneOptionsSuite) newCmd() *cobra.Command {
+	cmd := &cobra.Command{}
+	addCloneFlags(cmd)
+	return cmd
+}
+
+func (suite *CloneOptionsSuite) TestConfigLayers() {
+	suite.Equal(repo.CloneOptions{Depth: 1, SubmoduleJobs: 4, LFS: true}, cloneOptions(suite.newCmd(), storage.Repository{Name: "bitbucket.org/TheHipbot/weather"}), "Options for every repo should be used")
+	suite.Equal(repo.CloneOptions{Depth: 1, SingleBranch: true, SubmoduleJobs: 4, LFS: true}, cloneOptions(suite.newCmd(), storage.Repository{Name: "github.com/TheHipbot/hermes"}), "Remote options should be added")
+	suite.Equal(repo.CloneOptions{
+		SingleBranch:  true,
+		Filter:        "blob:none",
+		SparsePaths:   []string{"services/payments"},
+		SubmoduleJobs: 4,
+	}, cloneOptions(suite.newCmd(), storage.Repository{Name: "github.com/payments/monorepo"}), "Repo options should override the others")
+	suite.Equal(repo.CloneOptions{
+		Depth:          1,
+		Submodules:     true,
+		SubmoduleDepth: 1,
+		SubmoduleJobs:  4,
+		LFS:            true,
+	}, cloneOptions(suite.newCmd(), storage.Repository{Name: "gitlab.com/TheHipbot/weather"}), "Submodule options should be set by remote")
+}
+
+func (suite *CloneOptionsSuite) TestFlagsOverrideConfig() {
+	cmd := suite.newCmd()
+	cmd.Flags().Set("depth", "5")
+	cmd.Flags().Set("single-branch", "false")
+	cmd.Flags().Set("branch", "v1.0.0")
+	cmd.Flags().Set("sparse", "services/search,docs")
+	cmd.Flags().Set("recurse-submodules", "true")
+	cmd.Flags().Set("submodule-jobs", "8")
+	cmd.Flags().Set("lfs", "true")
+	suite.Equal(repo.CloneOptions{
+		Depth:         5,
+		Reference:     "v1.0.0",
+		Filter:        "blob:none",
+		SparsePaths:   []string{"services/search", "docs"},
+		Submodules:    true,
+		SubmoduleJobs: 8,
+		LFS:           true,
+	}, cloneOptions(cmd, storage.Repository{Name: "github.com/payments/monorepo"}))
+}
+
+func TestCloneOptionsSuite(t *testing.T) {
+	suite.Run(t, new(CloneOptionsSuite))
+}
@@ -0,0 +1,134 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/TheHipbot/hermes/pkg/repo"
+	"github.com/TheHipbot/hermes/pkg/storage"
+	"github.com/golang/mock/gomock"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/suite"
+)
+
+type CloneCmdSuite struct {
+	suite.Suite
+	*cmdFixture
+}
+
+func (suite *CloneCmdSuite) SetupTest() {
+	viper.Set("target_file_path", "")
+	suite.cmdFixture = newCmdFixture(suite.T(), []storage.Remote{
+		{Name: "gitlab.com", URL: "https://gitlab.com", Type: "gitlab", Protocol: "ssh"},
+	}, named(
+		"github.com/TheHipbot/hermes",
+		"github.com/TheHipbot/dotfiles",
+		"gitlab.com/TheHipbot/weather",
+	)...)
+}
+
+func (suite *CloneCmdSuite) TearDownTest() {
+	suite.tearDown()
+}
+
+func (suite *CloneCmdSuite) newCloneCmd() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Flags().Bool("all", false, "")
+	cmd.Flags().String("remote", "", "")
+	cmd.Flags().StringSlice("tag", []string{}, "")
+	cmd.Flags().IntP("jobs", "j", 2, "")
+	addCloneFlags(cmd)
+	return cmd
+}
+
+// progressMatcher matches clone options which equal its options once
+// their progress reporter, which must be set, is left out
+type progressMatcher struct {
+	opts repo.CloneOptions
+}
+
+func withProgress(opts repo.CloneOptions) gomock.Matcher {
+	return progressMatcher{opts: opts}
+}
+
+func (m progressMatcher) Matches(x interface{}) bool {
+	opts, ok := x.(*repo.CloneOptions)
+	if !ok || opts.Progress == nil {
+		return false
+	}
+	withoutProgress := *opts
+	withoutProgress.Progress = nil
+	return reflect.DeepEqual(m.opts, withoutProgress)
+}
+
+func (m progressMatcher) String() string {
+	return fmt.Sprintf("is equal to %v with a progress reporter", m.opts)
+}
+
+func (suite *CloneCmdSuite) TestCloneAll() {
+	suite.Nil(appFs.MkdirAll("/repos/github.com/TheHipbot/dotfiles", 0755))
+	suite.cloner.
+		EXPECT().
+		Clone("/repos/github.com/TheHipbot/hermes", withProgress(repo.CloneOptions{URL: "https://github.com/TheHipbot/hermes", LFS: true})).
+		Return(nil)
+	suite.cloner.
+		EXPECT().
+		Clone("/repos/gitlab.com/TheHipbot/weather", gomock.Any()).
+		Return(nil)
+
+	cmd := suite.newCloneCmd()
+	cmd.Flags().Set("all", "true")
+	cloneCmd.Run(cmd, []string{})
+
+	_, err := configFS.FS.Stat(fmt.Sprintf("%s%s", viper.GetString("config_path"), viper.GetString("target_file")))
+	suite.NotNil(err, "Clone should not write the target file")
+}
+
+func (suite *CloneCmdSuite) TestCloneByRemote() {
+	suite.cloner.
+		EXPECT().
+		Clone("/repos/github.com/TheHipbot/hermes", gomock.Any()).
+		Return(nil)
+	suite.cloner.
+		EXPECT().
+		Clone("/repos/github.com/TheHipbot/dotfiles", gomock.Any()).
+		Return(nil)
+
+	cmd := suite.newCloneCmd()
+	cmd.Flags().Set("remote", "github.com")
+	cloneCmd.Run(cmd, []string{"thehipbot"})
+}
+
+func (suite *CloneCmdSuite) TestCloneReposContinuesPastFailures() {
+	cloneErr := errors.New("clone failed")
+	suite.cloner.
+		EXPECT().
+		Clone("/repos/github.com/TheHipbot/dotfiles", gomock.Any()).
+		Return(cloneErr)
+	suite.cloner.
+		EXPECT().
+		Clone("/repos/github.com/TheHipbot/hermes", gomock.Any()).
+		Return(repo.ErrRepoAlreadyExists)
+	suite.cloner.
+		EXPECT().
+		Clone("/repos/gitlab.com/TheHipbot/weather", gomock.Any()).
+		Do(func(string, *repo.CloneOptions) {
+			suite.False(suite.cache.open, "The cache should be closed while repos clone")
+		}).
+		Return(nil)
+
+	suite.Nil(store.Open())
+	results := cloneRepos(suite.newCloneCmd(), store.SearchRepositories(""), 1)
+	suite.False(suite.cache.open, "The cache should be closed once the repos are saved")
+	suite.Len(results, 3, "Every repo should be cloned")
+	suite.Equal(cloneErr, results[0].Err)
+	suite.Nil(results[1].Err, "An existing repo should not be a failure")
+	suite.Nil(results[2].Err)
+}
+
+func TestCloneCmdSuite(t *testing.T) {
+	suite.Run(t, new(CloneCmdSuite))
+}
@@ -0,0 +1,150 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	completionShells = []string{
+		"bash",
+		"zsh",
+		"fish",
+	}
+)
+
+// completionCmd outputs the shell completion script for hermes
+var completionCmd = &cobra.Command{
+	Use:       "completion [bash|zsh|fish]",
+	Short:     "Outputs shell completion script for hermes",
+	ValidArgs: completionShells,
+	Args:      cobra.ExactValidArgs(1),
+	Run:       completionHandler,
+}
+
+func completionHandler(cmd *cobra.Command, args []string) {
+	var err error
+	switch args[0] {
+	case "bash":
+		err = rootCmd.GenBashCompletion(os.Stdout)
+	case "zsh":
+		err = rootCmd.GenZshCompletion(os.Stdout)
+	case "fish":
+		err = rootCmd.GenFishCompletion(os.Stdout, true)
+	}
+	if err != nil {
+		fmt.Printf("Error generating completion\n%s", err)
+		os.Exit(1)
+	}
+}
+
+// completeRepoNames completes the first argument with the names
+// of repos in the cache which contain the text being completed. Like
+// every completion below, nothing is completed when the cache cannot
+// be read, as file names are never valid candidates
+func completeRepoNames(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	if err := store.Open(); err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	defer store.Close()
+
+	names := []string{}
+	for _, r := range store.SearchRepositories(toComplete) {
+		names = append(names, r.Name)
+	}
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeTags completes with the tags used on repos in the cache
+func completeTags(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if err := store.Open(); err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	defer store.Close()
+
+	seen := map[string]bool{}
+	tags := []string{}
+	for _, r := range store.SearchRepositories("") {
+		for _, t := range r.Tags {
+			if !seen[t] && strings.HasPrefix(t, toComplete) {
+				seen[t] = true
+				tags = append(tags, t)
+			}
+		}
+	}
+	sort.Strings(tags)
+	return tags, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeRepoThenTags completes the first argument with repo
+// names and every following argument with tags
+func completeRepoThenTags(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) == 0 {
+		return completeRepoNames(cmd, args, toComplete)
+	}
+	return completeTags(cmd, args, toComplete)
+}
+
+// completeRemoteURLs completes the first argument with the URLs
+// of the remotes in the cache
+func completeRemoteURLs(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	if err := store.Open(); err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	defer store.Close()
+
+	urls := []string{}
+	for _, r := range store.ListRemotes() {
+		urls = append(urls, r.URL)
+	}
+	return urls, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeRemoteNames completes with the names of the remotes in the cache
+func completeRemoteNames(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if err := store.Open(); err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	defer store.Close()
+
+	names := []string{}
+	for _, r := range store.ListRemotes() {
+		names = append(names, r.Name)
+	}
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeWorkspaces completes with the names of the workspaces
+func completeWorkspaces(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if err := workspaces.Load(); err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	names := []string{}
+	for _, w := range workspaces.List() {
+		names = append(names, w.Name)
+	}
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
+
+func completeProtocols(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return protocols, cobra.ShellCompDirectiveNoFileComp
+}
+
+func completeDriverTypes(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	names := []string{}
+	for _, d := range drivers {
+		names = append(names, d.Name)
+	}
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
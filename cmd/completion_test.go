@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/TheHipbot/hermes/pkg/storage"
+	"github.com/TheHipbot/hermes/pkg/workspace"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/suite"
+	"gopkg.in/src-d/go-billy.v4/util"
+)
+
+type CompletionSuite struct {
+	suite.Suite
+}
+
+type completeFunc func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective)
+
+func (suite *CompletionSuite) SetupTest() {
+	setupCache(suite.T(), []storage.Remote{
+		{Name: "github.com", URL: "https://github.com", Type: "github", Protocol: "https"},
+		{Name: "gitlab.corp.com", URL: "https://gitlab.corp.com", Type: "gitlab", Protocol: "ssh"},
+	},
+		storage.Repository{Name: "github.com/TheHipbot/hermes", Tags: []string{"tools"}},
+		storage.Repository{Name: "github.com/TheHipbot/dotfiles", Tags: []string{"tools", "config"}},
+		storage.Repository{Name: "gitlab.corp.com/payments/ledger", Tags: []string{"payments"}},
+	)
+	workspaces.Put(&workspace.Workspace{Name: "payments"})
+	workspaces.Put(&workspace.Workspace{Name: "tools"})
+	suite.Nil(workspaces.Save())
+}
+
+func (suite *CompletionSuite) complete(fn completeFunc, args []string, toComplete string) []string {
+	candidates, directive := fn(&cobra.Command{}, args, toComplete)
+	suite.Equal(cobra.ShellCompDirectiveNoFileComp, directive, "Files should never be completed")
+	return candidates
+}
+
+func (suite *CompletionSuite) TestCompleteRepoNames() {
+	suite.ElementsMatch([]string{
+		"github.com/TheHipbot/hermes",
+		"github.com/TheHipbot/dotfiles",
+	}, suite.complete(completeRepoNames, nil, "TheHipbot"))
+	suite.Equal([]string{"gitlab.corp.com/payments/ledger"}, suite.complete(completeRepoNames, nil, "ledg"))
+	suite.Empty(suite.complete(completeRepoNames, []string{"hermes"}, "dot"), "Only the first argument should be completed")
+}
+
+func (suite *CompletionSuite) TestCompleteTags() {
+	suite.Equal([]string{"config", "payments", "tools"}, suite.complete(completeTags, nil, ""), "Tags should be listed once, sorted")
+	suite.Equal([]string{"tools"}, suite.complete(completeTags, nil, "to"))
+}
+
+func (suite *CompletionSuite) TestCompleteRemotes() {
+	suite.ElementsMatch([]string{"github.com", "gitlab.corp.com"}, suite.complete(completeRemoteNames, nil, "git"))
+	suite.ElementsMatch([]string{"https://github.com", "https://gitlab.corp.com"}, suite.complete(completeRemoteURLs, nil, "https"))
+	suite.Empty(suite.complete(completeRemoteURLs, []string{"https://github.com"}, "https"), "Only the first argument should be completed")
+}
+
+func (suite *CompletionSuite) TestCompleteWorkspaces() {
+	suite.ElementsMatch([]string{"payments", "tools"}, suite.complete(completeWorkspaces, nil, ""))
+}
+
+func (suite *CompletionSuite) TestUnreadableCache() {
+	suite.Nil(util.WriteFile(configFS.FS, configFS.CachePath(), []byte("{not json"), 0644))
+	suite.Nil(util.WriteFile(configFS.FS, "/workspaces.yml", []byte("{not yaml"), 0644))
+	suite.NotNil(store.Open(), "The cache should not be readable")
+	suite.NotNil(workspaces.Load(), "The workspaces should not be readable")
+	for name, fn := range map[string]completeFunc{
+		"repo names":   completeRepoNames,
+		"tags":         completeTags,
+		"remote names": completeRemoteNames,
+		"remote URLs":  completeRemoteURLs,
+		"workspaces":   completeWorkspaces,
+	} {
+		suite.Empty(suite.complete(fn, nil, ""), name)
+	}
+}
+
+func TestCompletionSuite(t *testing.T) {
+	suite.Run(t, new(CompletionSuite))
+}
@@ -0,0 +1,269 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+
+	"github.com/TheHipbot/hermes/pkg/storage"
+	"github.com/spf13/cobra"
+)
+
+var (
+	errNoExecSelection = errors.New("give --query, --tag, --workspace or --all to choose the repos to run in")
+
+	// errSkipped is the error for repos which were not run in
+	// because another repo failed with --fail-fast
+	errSkipped = errors.New("skipped after an earlier failure")
+)
+
+func init() {
+	execCmd.Flags().StringP("query", "q", "", "run in repos matching the search")
+	execCmd.Flags().StringSlice("tag", []string{}, "run in repos with the tag")
+	execCmd.Flags().StringP("workspace", "w", "", "run in the repos of the workspace")
+	execCmd.Flags().Bool("all", false, "run in every cloned repo")
+	execCmd.Flags().IntP("jobs", "j", 4, "number of repos to run in at once")
+	execCmd.Flags().Bool("group", false, "print the output of each repo together once it finishes instead of prefixing each line")
+	execCmd.Flags().Bool("fail-fast", false, "stop once the command fails in any repo")
+	execCmd.Flags().Bool("json", false, "print a json report of every repo once done")
+	execCmd.RegisterFlagCompletionFunc("tag", completeTags)
+	execCmd.RegisterFlagCompletionFunc("workspace", completeWorkspaces)
+}
+
+var execCmd = &cobra.Command{
+	Use:   "exec [flags] -- [command...]",
+	Short: "Run a command in many cloned repos",
+	Long: `Exec runs the command in the directory of every cloned repo matching
+the --query, --tag and --workspace given (or every cloned repo with --all),
+several at a time. Each line of output is prefixed with the repo name,
+or with --group the output of each repo is printed together. Hermes exits
+with a non-zero code if the command failed in any repo.`,
+	Example: `  hermes exec --tag backend -- go mod tidy
+  hermes exec -w payments --group -- git log -1 --oneline`,
+	Args: cobra.MinimumNArgs(1),
+	Run:  execHandler,
+}
+
+// execResult is the outcome of running the command in a repo
+type execResult struct {
+	Repo     string `json:"repo"`
+	Path     string `json:"path"`
+	ExitCode int    `json:"exit_code"`
+	Stdout   string `json:"stdout,omitempty"`
+	Stderr   string `json:"stderr,omitempty"`
+	Error    string `json:"error,omitempty"`
+	Skipped  bool   `json:"skipped,omitempty"`
+}
+
+func execHandler(cmd *cobra.Command, args []string) {
+	query, _ := cmd.Flags().GetString("query")
+	tags, _ := cmd.Flags().GetStringSlice("tag")
+	wsName, _ := cmd.Flags().GetString("workspace")
+	all, _ := cmd.Flags().GetBool("all")
+	jobs, _ := cmd.Flags().GetInt("jobs")
+	group, _ := cmd.Flags().GetBool("group")
+	failFast, _ := cmd.Flags().GetBool("fail-fast")
+	jsonReport, _ := cmd.Flags().GetBool("json")
+
+	if query == "" && len(tags) == 0 && wsName == "" && !all {
+		fmt.Println(errNoExecSelection)
+		os.Exit(ExitInvalidArguments)
+	}
+
+	repos := execRepos(query, tags, wsName)
+	results := runInRepos(repos, args, jobs, group, failFast, jsonReport)
+
+	failed, skipped := 0, 0
+	for _, res := range results {
+		if res.Skipped {
+			skipped++
+		} else if res.ExitCode != 0 {
+			failed++
+		}
+	}
+
+	if jsonReport {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(results); err != nil {
+			fmt.Fprintf(os.Stderr, "Error writing report\n%s\n", err)
+			os.Exit(1)
+		}
+	} else {
+		for _, res := range results {
+			switch {
+			case res.Skipped, res.ExitCode == 0:
+			case res.Error != "":
+				fmt.Fprintf(os.Stderr, "error with %s\n%s\n", res.Repo, res.Error)
+			default:
+				fmt.Fprintf(os.Stderr, "%s exited with code %d\n", res.Repo, res.ExitCode)
+			}
+		}
+		fmt.Fprintf(os.Stderr, "%d succeeded, %d failed, %d skipped\n", len(results)-failed-skipped, failed, skipped)
+	}
+
+	if failed > 0 {
+		os.Exit(1)
+	}
+}
+
+// execRepos returns the cloned repos matching the search and tags
+// which are in the workspace, when one is given
+func execRepos(query string, tags []string, wsName string) []storage.Repository {
+	terms := []string{}
+	if query != "" {
+		terms = append(terms, query)
+	}
+	for _, t := range tags {
+		terms = append(terms, fmt.Sprintf("tag:%s", t))
+	}
+
+	openStore()
+	defer store.Close()
+
+	repos := store.SearchRepositories(strings.Join(terms, " "))
+	if wsName != "" {
+		loadWorkspaces()
+		members := map[string]bool{}
+		for _, r := range workspaceMembers(getWorkspace(wsName)) {
+			members[r.Name] = true
+		}
+		inWorkspace := []storage.Repository{}
+		for _, r := range repos {
+			if members[r.Name] {
+				inWorkspace = append(inWorkspace, r)
+			}
+		}
+		repos = inWorkspace
+	}
+
+	cloned := []storage.Repository{}
+	for _, r := range repos {
+		if _, err := appFs.Stat(r.Path); err == nil {
+			cloned = append(cloned, r)
+		}
+	}
+	return cloned
+}
+
+// runInRepos runs the command in every repo with up to jobs at once.
+// Output is streamed with each line prefixed by the repo name, printed
+// together per repo with group, or only captured in the results for
+// a json report
+func runInRepos(repos []storage.Repository, command []string, jobs int, group, failFast, capture bool) []execResult {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var mu sync.Mutex
+	results := make([]execResult, len(repos))
+	indexes := map[string]int{}
+	for i, r := range repos {
+		indexes[r.Name] = i
+	}
+
+	forEachRepo(repos, jobs, func(r storage.Repository) error {
+		res := execResult{
+			Repo: r.Name,
+			Path: r.Path,
+		}
+		defer func() {
+			results[indexes[r.Name]] = res
+		}()
+		if ctx.Err() != nil {
+			res.Skipped = true
+			res.ExitCode = -1
+			res.Error = errSkipped.Error()
+			return errSkipped
+		}
+
+		var stdout, stderr bytes.Buffer
+		var outW, errW io.Writer = &stdout, &stderr
+		var prefixOut, prefixErr *prefixWriter
+		switch {
+		case capture:
+		case group:
+			errW = &stdout
+		default:
+			prefixOut = &prefixWriter{mu: &mu, out: os.Stdout, prefix: r.Name}
+			prefixErr = &prefixWriter{mu: &mu, out: os.Stderr, prefix: r.Name}
+			outW, errW = prefixOut, prefixErr
+		}
+
+		err := runCommand(ctx, r.Path, command, outW, errW)
+
+		if prefixOut != nil {
+			prefixOut.Flush()
+			prefixErr.Flush()
+		} else if !capture {
+			mu.Lock()
+			fmt.Printf("==> %s <==\n%s", r.Name, stdout.String())
+			mu.Unlock()
+		}
+		if capture {
+			res.Stdout, res.Stderr = stdout.String(), stderr.String()
+		}
+
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			res.ExitCode = exitErr.ExitCode()
+		} else if err != nil {
+			res.ExitCode = -1
+			res.Error = err.Error()
+		}
+		if err != nil && failFast {
+			cancel()
+		}
+		return err
+	})
+	return results
+}
+
+// runCommand runs the command in dir, it is killed if ctx is done
+func runCommand(ctx context.Context, dir string, command []string, stdout, stderr io.Writer) error {
+	c := exec.CommandContext(ctx, command[0], command[1:]...)
+	c.Dir = dir
+	c.Stdout = stdout
+	c.Stderr = stderr
+	return c.Run()
+}
+
+// prefixWriter writes each line to out prefixed with the repo name,
+// holding any partial line until it is completed or flushed
+type prefixWriter struct {
+	mu     *sync.Mutex
+	out    io.Writer
+	prefix string
+	buf    []byte
+}
+
+func (w *prefixWriter) Write(p []byte) (int, error) {
+	w.buf = append(w.buf, p...)
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+		if i < 0 {
+			return len(p), nil
+		}
+		w.writeLine(w.buf[:i+1])
+		w.buf = w.buf[i+1:]
+	}
+}
+
+// Flush writes any partial line left in the buffer
+func (w *prefixWriter) Flush() {
+	if len(w.buf) > 0 {
+		w.writeLine(append(w.buf, '\n'))
+		w.buf = nil
+	}
+}
+
+func (w *prefixWriter) writeLine(line []byte) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	fmt.Fprintf(w.out, "%s: %s", w.prefix, line)
+}
@@ -0,0 +1,114 @@
+package cmd
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/TheHipbot/hermes/pkg/storage"
+	"github.com/TheHipbot/hermes/pkg/workspace"
+	"github.com/stretchr/testify/suite"
+)
+
+type ExecCmdSuite struct {
+	suite.Suite
+	dir string
+}
+
+func (suite *ExecCmdSuite) SetupTest() {
+	dir, err := ioutil.TempDir("", "hermes-exec")
+	suite.Nil(err, "Setup should create a temp dir")
+	suite.dir = dir
+
+	repos := []storage.Repository{
+		{Name: "github.com/payments/api", Tags: []string{"backend"}},
+		{Name: "github.com/payments/web"},
+		{Name: "github.com/search/api", Tags: []string{"backend"}},
+		{Name: "github.com/search/indexer", Tags: []string{"backend"}},
+	}
+	setupCache(suite.T(), nil, repos...)
+	for _, r := range repos[:3] {
+		suite.Nil(appFs.MkdirAll("/repos/"+r.Name, 0755))
+	}
+}
+
+func (suite *ExecCmdSuite) TearDownTest() {
+	os.RemoveAll(suite.dir)
+}
+
+// tempRepos creates a directory for each repo name
+func (suite *ExecCmdSuite) tempRepos(names ...string) []storage.Repository {
+	repos := []storage.Repository{}
+	for _, name := range names {
+		path := filepath.Join(suite.dir, name)
+		suite.Nil(os.MkdirAll(path, 0755))
+		repos = append(repos, storage.Repository{
+			Name: name,
+			Path: path,
+		})
+	}
+	return repos
+}
+
+func (suite *ExecCmdSuite) TestExecRepos() {
+	names := func(repos []storage.Repository) []string {
+		result := []string{}
+		for _, r := range repos {
+			result = append(result, r.Name)
+		}
+		return result
+	}
+
+	suite.Equal([]string{"github.com/payments/api", "github.com/search/api"}, names(execRepos("", []string{"backend"}, "")), "Only cloned repos should be run in")
+	suite.Equal([]string{"github.com/payments/api", "github.com/search/api"}, names(execRepos("api", nil, "")))
+
+	workspaces.Put(&workspace.Workspace{
+		Name:    "payments",
+		Queries: []string{"payments"},
+	})
+	suite.Nil(workspaces.Save())
+	suite.Equal([]string{"github.com/payments/api"}, names(execRepos("", []string{"backend"}, "payments")), "Workspace and tag should both match")
+}
+
+func (suite *ExecCmdSuite) TestRunInReposCapture() {
+	repos := suite.tempRepos("pass", "fail")
+	results := runInRepos(repos, []string{"sh", "-c", `basename "$PWD"; echo err >&2; test "$(basename "$PWD")" = pass`}, 2, false, false, true)
+	suite.Len(results, 2)
+	suite.Equal("pass", results[0].Repo, "Results should be in the order of the repos")
+	suite.Equal(0, results[0].ExitCode)
+	suite.Equal("pass\n", results[0].Stdout, "Command should run in the repo path")
+	suite.Equal("err\n", results[0].Stderr)
+	suite.Equal(1, results[1].ExitCode, "Exit code should be reported")
+	suite.False(results[1].Skipped)
+}
+
+func (suite *ExecCmdSuite) TestRunInReposFailFast() {
+	repos := suite.tempRepos("first", "second", "third")
+	results := runInRepos(repos, []string{"false"}, 1, false, true, true)
+	suite.Equal(1, results[0].ExitCode)
+	suite.True(results[1].Skipped, "Repos after a failure should be skipped")
+	suite.True(results[2].Skipped)
+}
+
+func (suite *ExecCmdSuite) TestRunInReposMissingCommand() {
+	results := runInRepos(suite.tempRepos("repo"), []string{"hermes-no-such-command"}, 1, false, false, true)
+	suite.Equal(-1, results[0].ExitCode)
+	suite.NotEmpty(results[0].Error, "A command which cannot start should be reported")
+}
+
+func (suite *ExecCmdSuite) TestPrefixWriter() {
+	out := &bytes.Buffer{}
+	w := &prefixWriter{mu: &sync.Mutex{}, out: out, prefix: "github.com/TheHipbot/hermes"}
+	w.Write([]byte("first\nsec"))
+	w.Write([]byte("ond\npartial"))
+	suite.Equal("github.com/TheHipbot/hermes: first\ngithub.com/TheHipbot/hermes: second\n", out.String(), "Only full lines should be written")
+	w.Flush()
+	suite.Equal("github.com/TheHipbot/hermes: first\ngithub.com/TheHipbot/hermes: second\ngithub.com/TheHipbot/hermes: partial\n", out.String())
+}
+
+func TestExecCmdSuite(t *testing.T) {
+	suite.Run(t, new(ExecCmdSuite))
+}
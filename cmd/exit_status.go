@@ -1,6 +1,10 @@
 package cmd
 
 const (
+	// ExitInputRequired status for when user input is required
+	// but hermes is running non-interactively
+	ExitInputRequired = 3
+
 	// ExitCannotExecute status for cannot execute
 	ExitCannotExecute = 126
 
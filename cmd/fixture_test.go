@@ -0,0 +1,114 @@
+package cmd
+
+import (
+	"os"
+	"testing"
+
+	"github.com/TheHipbot/hermes/mock"
+	"github.com/TheHipbot/hermes/pkg/fs"
+	"github.com/TheHipbot/hermes/pkg/repo"
+	"github.com/TheHipbot/hermes/pkg/storage"
+	"github.com/TheHipbot/hermes/pkg/workspace"
+	"github.com/golang/mock/gomock"
+	"gopkg.in/src-d/go-billy.v4/memfs"
+)
+
+// cmdFixture points the command globals at memfs config and app
+// filesystems and a mock cloner, with a cache holding its repos
+type cmdFixture struct {
+	ctrl   *gomock.Controller
+	cloner *mock.MockCloner
+	cache  *trackedStore
+	// exitCode is the code a handler exited with, -1 if it did not
+	exitCode int
+}
+
+// trackedStore records whether the cache is open, so tests can check
+// it is closed while repos are cloned, pulled or synced
+type trackedStore struct {
+	storage.Storage
+	open bool
+}
+
+func (s *trackedStore) Open() error {
+	err := s.Storage.Open()
+	s.open = err == nil
+	return err
+}
+
+func (s *trackedStore) Close() error {
+	s.open = false
+	return s.Storage.Close()
+}
+
+// newCmdFixture sets up a fixture whose cache holds the repos, a repo
+// without a path is given /repos/<name>. Remotes are added before the
+// repos so a repo is added to its remote's entry
+func newCmdFixture(t *testing.T, remotes []storage.Remote, repos ...storage.Repository) *cmdFixture {
+	f := &cmdFixture{
+		ctrl:     gomock.NewController(t),
+		exitCode: -1,
+	}
+	exit = func(code int) {
+		f.exitCode = code
+	}
+	f.cloner = mock.NewMockCloner(f.ctrl)
+	newCloner = func(string) (repo.Cloner, error) {
+		return f.cloner, nil
+	}
+	setupCache(t, remotes, repos...)
+	f.cache = &trackedStore{Storage: store}
+	store = f.cache
+	return f
+}
+
+// setupCache creates the memfs filesystems, the workspace store and a
+// cache holding the remotes and repos, leaving the cache closed
+func setupCache(t *testing.T, remotes []storage.Remote, repos ...storage.Repository) {
+	configFS = &fs.ConfigFS{
+		FS: memfs.New(),
+	}
+	configFS.Setup()
+	appFs = memfs.New()
+	workspaces = workspace.NewStore(configFS.FS, "/workspaces.yml")
+	store = storage.NewFileStorage(configFS.FS, configFS.CachePath())
+	if err := store.Open(); err != nil {
+		t.Fatalf("cache should open: %s", err)
+	}
+	defer store.Close()
+
+	for _, r := range remotes {
+		if err := store.AddRemote(r.URL, r.Name, r.Type, r.Protocol); err != nil {
+			t.Fatalf("remote %s should be added: %s", r.Name, err)
+		}
+	}
+	for _, r := range repos {
+		repoToAdd := r
+		if repoToAdd.Path == "" {
+			repoToAdd.Path = "/repos/" + repoToAdd.Name
+		}
+		if err := store.AddRepository(&repoToAdd); err != nil {
+			t.Fatalf("repo %s should be added: %s", r.Name, err)
+		}
+	}
+	if err := store.Save(); err != nil {
+		t.Fatalf("cache should be saved: %s", err)
+	}
+}
+
+// tearDown checks the mock cloner's expectations and restores
+// newCloner and exit
+func (f *cmdFixture) tearDown() {
+	f.ctrl.Finish()
+	newCloner = repo.NewCloner
+	exit = os.Exit
+}
+
+// named returns repos with the names and default paths
+func named(names ...string) []storage.Repository {
+	repos := make([]storage.Repository, 0, len(names))
+	for _, name := range names {
+		repos = append(repos, storage.Repository{Name: name})
+	}
+	return repos
+}
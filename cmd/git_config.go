@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+var errInvalidGitConfig = errors.New("git config must be given as section.name=value")
+
+// remoteSettings are the settings for a remote in the remotes section of
+// the config, given in a list as viper splits map keys containing dots
+type remoteSettings struct {
+	Name         string            `mapstructure:"name"`
+	Alias        string            `mapstructure:"alias"`
+	PathTemplate string            `mapstructure:"path_template"`
+	GitConfig    map[string]string `mapstructure:"git_config"`
+}
+
+// remoteGitConfig returns the git config set for the remote of the named
+// repo, entries in the config override those set with remote edit
+func remoteGitConfig(name string) map[string]string {
+	remoteName := strings.Split(name, "/")[0]
+	gitConfig := map[string]string{}
+	if remote, ok := store.SearchRemote(remoteName); ok {
+		for k, v := range remote.GitConfig {
+			gitConfig[k] = v
+		}
+	}
+
+	settings := []remoteSettings{}
+	viper.UnmarshalKey("remotes", &settings)
+	for _, s := range settings {
+		if s.Name != remoteName {
+			continue
+		}
+		for k, v := range s.GitConfig {
+			gitConfig[k] = v
+		}
+	}
+
+	if len(gitConfig) == 0 {
+		return nil
+	}
+	return gitConfig
+}
+
+// parseGitConfigEntry splits a section.name=value argument
+func parseGitConfigEntry(entry string) (string, string, error) {
+	parts := strings.SplitN(entry, "=", 2)
+	if len(parts) != 2 || !validGitConfigKey(parts[0]) {
+		return "", "", errInvalidGitConfig
+	}
+	return parts[0], parts[1], nil
+}
+
+// validGitConfigKey reports whether the key has a section and a name
+func validGitConfigKey(key string) bool {
+	first := strings.Index(key, ".")
+	return first > 0 && strings.LastIndex(key, ".") < len(key)-1
+}
@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/TheHipbot/hermes/pkg/storage"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/suite"
+)
+
+type GitConfigCmdSuite struct {
+	suite.Suite
+	*cmdFixture
+}
+
+func (suite *GitConfigCmdSuite) SetupTest() {
+	suite.cmdFixture = newCmdFixture(suite.T(), []storage.Remote{
+		{Name: "gitlab.corp.com", URL: "https://gitlab.corp.com", Type: "gitlab", Protocol: "ssh"},
+		{Name: "github.com", URL: "https://github.com", Type: "github", Protocol: "https"},
+	}, named(
+		"gitlab.corp.com/payments/ledger",
+		"gitlab.corp.com/payments/api",
+		"github.com/TheHipbot/hermes",
+	)...)
+
+	viper.SetConfigType("yaml")
+	suite.Nil(viper.ReadConfig(bytes.NewBufferString(`
+remotes:
+- name: gitlab.corp.com
+  git_config:
+    commit.gpgsign: "true"
+`)))
+}
+
+func (suite *GitConfigCmdSuite) TearDownTest() {
+	suite.tearDown()
+	viper.ReadConfig(bytes.NewBufferString(""))
+}
+
+func (suite *GitConfigCmdSuite) editRemote(name string, set, unset []string) {
+	cmd := &cobra.Command{}
+	cmd.Flags().StringArray("git-config", []string{}, "")
+	cmd.Flags().StringArray("unset-git-config", []string{}, "")
+	for _, entry := range set {
+		cmd.Flags().Set("git-config", entry)
+	}
+	for _, key := range unset {
+		cmd.Flags().Set("unset-git-config", key)
+	}
+	remoteEditCmd.Run(cmd, []string{name})
+}
+
+func (suite *GitConfigCmdSuite) TestRemoteEdit() {
+	suite.editRemote("gitlab.corp.com", []string{"user.email=jeremy@corp.com", "user.name=Jeremy", "commit.gpgsign=false"}, nil)
+	suite.editRemote("gitlab.corp.com", nil, []string{"user.name"})
+
+	suite.Nil(store.Open())
+	defer store.Close()
+	r, _ := store.SearchRemote("gitlab.corp.com")
+	suite.Equal(map[string]string{
+		"user.email":     "jeremy@corp.com",
+		"commit.gpgsign": "false",
+	}, r.GitConfig, "Git config should be saved on the remote")
+	suite.Equal(map[string]string{
+		"user.email":     "jeremy@corp.com",
+		"commit.gpgsign": "true",
+	}, remoteGitConfig("gitlab.corp.com/payments/ledger"), "The config file should override the remote")
+	suite.Nil(remoteGitConfig("github.com/TheHipbot/hermes"), "A remote without git config should have none")
+}
+
+func (suite *GitConfigCmdSuite) TestParseGitConfigEntry() {
+	key, value, err := parseGitConfigEntry("url.git@gitlab.corp.com:.insteadOf=https://gitlab.corp.com/")
+	suite.Nil(err)
+	suite.Equal("url.git@gitlab.corp.com:.insteadOf", key)
+	suite.Equal("https://gitlab.corp.com/", value)
+
+	for _, entry := range []string{"user.email", "email=jeremy@corp.com", ".email=x", "user.=x"} {
+		_, _, err := parseGitConfigEntry(entry)
+		suite.Equal(errInvalidGitConfig, err, entry)
+	}
+}
+
+func (suite *GitConfigCmdSuite) TestApplyConfig() {
+	suite.Nil(appFs.MkdirAll("/repos/gitlab.corp.com/payments/ledger", 0755))
+	suite.Nil(appFs.MkdirAll("/repos/github.com/TheHipbot/hermes", 0755))
+	suite.cloner.
+		EXPECT().
+		Configure("/repos/gitlab.corp.com/payments/ledger", map[string]string{"commit.gpgsign": "true"}).
+		Return(nil)
+
+	repoApplyConfigCommand.Run(&cobra.Command{}, []string{})
+}
+
+func TestGitConfigCmdSuite(t *testing.T) {
+	suite.Run(t, new(GitConfigCmdSuite))
+}
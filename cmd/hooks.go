@@ -0,0 +1,134 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/TheHipbot/hermes/pkg/storage"
+	"github.com/spf13/viper"
+)
+
+// defaultHookTimeout is how long a hook may run when it sets no timeout
+const defaultHookTimeout = 5 * time.Minute
+
+// hook is a shell command run in a repo after it is cloned. It runs for
+// repos of the remote and repos whose name matches the glob, when both
+// are empty it runs for every repo
+type hook struct {
+	Name    string        `mapstructure:"name"`
+	Remote  string        `mapstructure:"remote"`
+	Match   string        `mapstructure:"match"`
+	Run     string        `mapstructure:"run"`
+	Timeout time.Duration `mapstructure:"timeout"`
+}
+
+// matches reports whether the hook runs for the repo
+func (h hook) matches(r storage.Repository) bool {
+	if h.Remote != "" && h.Remote != strings.Split(r.Name, "/")[0] {
+		return false
+	}
+	if h.Match != "" {
+		// the glob is checked when the hooks are read
+		if ok, _ := path.Match(h.Match, r.Name); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// label names the hook in errors, by its name or else its command
+func (h hook) label() string {
+	if h.Name != "" {
+		return h.Name
+	}
+	return h.Run
+}
+
+// postCloneHooks returns the post_clone_hooks from the config
+// which run for the repo, in the order they are listed. Every hook's
+// glob is checked so a bad one is reported even when it is not used
+func postCloneHooks(r storage.Repository) ([]hook, error) {
+	hooks := []hook{}
+	if err := viper.UnmarshalKey("post_clone_hooks", &hooks); err != nil {
+		return nil, fmt.Errorf("invalid post_clone_hooks config\n%s", err)
+	}
+	for _, h := range hooks {
+		if _, err := path.Match(h.Match, ""); err != nil {
+			return nil, fmt.Errorf("invalid post_clone_hooks config\nhook %q has a bad match glob %q", h.label(), h.Match)
+		}
+	}
+
+	matched := []hook{}
+	for _, h := range hooks {
+		if h.Run != "" && h.matches(r) {
+			matched = append(matched, h)
+		}
+	}
+	return matched, nil
+}
+
+// runPostCloneHooks runs the hooks for the newly cloned repo in its
+// directory, stopping at the first which fails or times out as later
+// hooks may depend on it
+func runPostCloneHooks(r storage.Repository, out io.Writer) error {
+	hooks, err := postCloneHooks(r)
+	if err != nil {
+		return err
+	}
+	for _, h := range hooks {
+		if err := runHook(h, r, out); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// runHook runs the hook with sh, with the repo described by
+// HERMES_REPO_NAME, HERMES_REPO_PATH and HERMES_REMOTE
+func runHook(h hook, r storage.Repository, out io.Writer) error {
+	timeout := h.Timeout
+	if timeout <= 0 {
+		timeout = defaultHookTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	c := exec.Command("sh", "-c", h.Run)
+	c.Dir = r.Path
+	c.Env = append(os.Environ(),
+		fmt.Sprintf("HERMES_REPO_NAME=%s", r.Name),
+		fmt.Sprintf("HERMES_REPO_PATH=%s", r.Path),
+		fmt.Sprintf("HERMES_REMOTE=%s", strings.Split(r.Name, "/")[0]),
+	)
+	c.Stdout = out
+	c.Stderr = out
+	setProcessGroup(c)
+
+	if err := c.Start(); err != nil {
+		return fmt.Errorf("post-clone hook %q failed in %s\n%s", h.label(), r.Path, err)
+	}
+	done := make(chan error, 1)
+	go func() {
+		done <- c.Wait()
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			return fmt.Errorf("post-clone hook %q failed in %s\n%s", h.label(), r.Path, err)
+		}
+		return nil
+	case <-ctx.Done():
+		// killing only sh would leave the commands it started running,
+		// and holding its output open so Wait would not return
+		killProcessGroup(c)
+		<-done
+		return fmt.Errorf("post-clone hook %q timed out after %s in %s", h.label(), timeout, r.Path)
+	}
+}
@@ -0,0 +1,138 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/TheHipbot/hermes/pkg/storage"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/suite"
+)
+
+type HooksSuite struct {
+	suite.Suite
+	dir string
+}
+
+func (s *HooksSuite) SetupTest() {
+	var err error
+	s.dir, err = ioutil.TempDir("", "hermes-hooks")
+	s.Nil(err)
+	viper.SetConfigType("yaml")
+	s.Nil(viper.ReadConfig(bytes.NewBufferString(`
+post_clone_hooks:
+- name: work email
+  remote: gitlab.corp.com
+  run: echo email
+- name: bootstrap
+  match: github.com/TheHipbot/*
+  run: echo "$HERMES_REPO_NAME $HERMES_REMOTE $(pwd)"
+- run: echo everywhere
+`)))
+}
+
+func (s *HooksSuite) TearDownTest() {
+	os.RemoveAll(s.dir)
+	viper.ReadConfig(bytes.NewBufferString(""))
+}
+
+func (s *HooksSuite) TestMatching() {
+	hooks, err := postCloneHooks(storage.Repository{Name: "gitlab.corp.com/payments/ledger"})
+	s.Nil(err)
+	s.Len(hooks, 2)
+	s.Equal("work email", hooks[0].label())
+	s.Equal("echo everywhere", hooks[1].label(), "A hook without a name should be labelled by its command")
+
+	hooks, err = postCloneHooks(storage.Repository{Name: "github.com/TheHipbot/hermes"})
+	s.Nil(err)
+	s.Len(hooks, 2)
+	s.Equal("bootstrap", hooks[0].label())
+
+	hooks, err = postCloneHooks(storage.Repository{Name: "github.com/TheHipbot/hermes/sub"})
+	s.Nil(err)
+	s.Len(hooks, 1, "The glob should not match across a /")
+}
+
+func (s *HooksSuite) TestBadMatchGlob() {
+	s.Nil(viper.ReadConfig(bytes.NewBufferString(`
+post_clone_hooks:
+- name: bootstrap
+  remote: gitlab.corp.com
+  match: github.com/[TheHipbot/*
+  run: echo bootstrap
+`)))
+	_, err := postCloneHooks(storage.Repository{Name: "github.com/TheHipbot/hermes"})
+	s.EqualError(err, "invalid post_clone_hooks config\nhook \"bootstrap\" has a bad match glob \"github.com/[TheHipbot/*\"",
+		"The glob should be checked even when the remote does not match")
+}
+
+func (s *HooksSuite) TestRunPostCloneHooks() {
+	r := storage.Repository{
+		Name: "github.com/TheHipbot/hermes",
+		Path: s.dir,
+	}
+	out := &bytes.Buffer{}
+	s.Nil(runPostCloneHooks(r, out))
+
+	dir, _ := filepath.EvalSymlinks(s.dir)
+	s.Equal("github.com/TheHipbot/hermes github.com "+dir+"\neverywhere\n", out.String())
+}
+
+func (s *HooksSuite) TestRunPostCloneHooksFailure() {
+	s.Nil(viper.ReadConfig(bytes.NewBufferString(`
+post_clone_hooks:
+- name: bootstrap
+  run: exit 3
+- run: echo never
+`)))
+	out := &bytes.Buffer{}
+	err := runPostCloneHooks(storage.Repository{Name: "github.com/TheHipbot/hermes", Path: s.dir}, out)
+	s.EqualError(err, `post-clone hook "bootstrap" failed in `+s.dir+"\nexit status 3")
+	s.Empty(out.String(), "Hooks after a failure should not run")
+}
+
+func (s *HooksSuite) TestRunHookTimeout() {
+	s.Nil(viper.ReadConfig(bytes.NewBufferString(`
+post_clone_hooks:
+- name: slow
+  run: sleep 5 & echo $! > child.pid; wait
+  timeout: 100ms
+`)))
+	out := &bytes.Buffer{}
+	started := time.Now()
+	err := runPostCloneHooks(storage.Repository{Name: "github.com/TheHipbot/hermes", Path: s.dir}, out)
+	s.EqualError(err, `post-clone hook "slow" timed out after 100ms in `+s.dir)
+	s.True(time.Since(started) < 2*time.Second, "The hook should not wait on the commands it started")
+
+	raw, err := ioutil.ReadFile(filepath.Join(s.dir, "child.pid"))
+	s.Nil(err)
+	pid, err := strconv.Atoi(strings.TrimSpace(string(raw)))
+	s.Nil(err)
+	for wait := 0; processRunning(pid) && wait < 20; wait++ {
+		time.Sleep(50 * time.Millisecond)
+	}
+	s.False(processRunning(pid), "The commands the hook started should be killed")
+}
+
+// processRunning reports whether the process is running, a killed
+// process left as a zombie until it is reaped is not running
+func processRunning(pid int) bool {
+	p, _ := os.FindProcess(pid)
+	if p.Signal(syscall.Signal(0)) != nil {
+		return false
+	}
+	stat, err := ioutil.ReadFile(fmt.Sprintf("/proc/%d/stat", pid))
+	return err != nil || !strings.Contains(string(stat), ") Z ")
+}
+
+func TestHooksSuite(t *testing.T) {
+	suite.Run(t, new(HooksSuite))
+}
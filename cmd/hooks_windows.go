@@ -0,0 +1,17 @@
+//go:build windows
+// +build windows
+
+package cmd
+
+import (
+	"os/exec"
+)
+
+// setProcessGroup does nothing on windows, which has no process groups
+func setProcessGroup(c *exec.Cmd) {}
+
+// killProcessGroup kills the started command, the processes it
+// started are left running on windows
+func killProcessGroup(c *exec.Cmd) error {
+	return c.Process.Kill()
+}
@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/TheHipbot/hermes/pkg/storage"
+)
+
+// repoResult is the outcome of running an operation on a repo
+type repoResult struct {
+	Repo storage.Repository
+	Err  error
+}
+
+// forEachRepo runs fn on every repo with up to jobs running at once,
+// the results are returned in the same order as repos
+func forEachRepo(repos []storage.Repository, jobs int, fn func(r storage.Repository) error) []repoResult {
+	if jobs < 1 {
+		jobs = 1
+	}
+	results := make([]repoResult, len(repos))
+	indexes := make(chan int)
+
+	var wg sync.WaitGroup
+	for w := 0; w < jobs && w < len(repos); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				results[i] = repoResult{
+					Repo: repos[i],
+					Err:  fn(repos[i]),
+				}
+			}
+		}()
+	}
+
+	for i := range repos {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+	return results
+}
+
+// runOnRepos runs fn on every repo as forEachRepo does, writing a
+// progress line to out as each repo finishes
+func runOnRepos(out io.Writer, repos []storage.Repository, jobs int, action string, fn func(r storage.Repository) error) []repoResult {
+	var mu sync.Mutex
+	done := 0
+	return forEachRepo(repos, jobs, func(r storage.Repository) error {
+		err := fn(r)
+
+		mu.Lock()
+		defer mu.Unlock()
+		done++
+		if err != nil {
+			fmt.Fprintf(out, "[%d/%d] %s failed\n", done, len(repos), r.Name)
+		} else {
+			fmt.Fprintf(out, "[%d/%d] %s %s\n", done, len(repos), action, r.Name)
+		}
+		return err
+	})
+}
+
+// printFailures writes the error for every repo which failed,
+// returning how many failed
+func printFailures(out io.Writer, results []repoResult) int {
+	failed := 0
+	for _, res := range results {
+		if res.Err != nil {
+			failed++
+			fmt.Fprintf(out, "error with %s\n%s\n", res.Repo.Name, res.Err)
+		}
+	}
+	return failed
+}
@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/TheHipbot/hermes/pkg/storage"
+	homedir "github.com/mitchellh/go-homedir"
+	"github.com/spf13/viper"
+)
+
+var errEmptyRepoPath = errors.New("path_template gave an empty path")
+
+// pathTemplateData is what a path_template is executed with
+type pathTemplateData struct {
+	// Name is the full name of the repo, such as github.com/TheHipbot/hermes
+	Name string
+	// Remote is the name of the repo's remote, such as github.com
+	Remote string
+	// Alias is the alias set for the remote, or else its name
+	Alias string
+	// Owner is the user or groups the repo belongs to, such as TheHipbot
+	Owner string
+	// Repo is the last part of the repo's name, such as hermes
+	Repo     string
+	Language string
+}
+
+// newPathTemplateData splits the repo's name into the parts
+// given to path templates
+func newPathTemplateData(r storage.Repository) pathTemplateData {
+	parts := strings.Split(r.Name, "/")
+	data := pathTemplateData{
+		Name:     r.Name,
+		Remote:   parts[0],
+		Alias:    parts[0],
+		Repo:     parts[len(parts)-1],
+		Language: r.Language,
+	}
+	if len(parts) > 2 {
+		data.Owner = strings.Join(parts[1:len(parts)-1], "/")
+	}
+	return data
+}
+
+// pathTemplate returns the path_template for the remote, set for the
+// remote in the remotes config or else for every repo, along with the
+// remote's alias
+func pathTemplate(remote string) (string, string) {
+	tmpl := viper.GetString("path_template")
+	alias := remote
+
+	settings := []remoteSettings{}
+	viper.UnmarshalKey("remotes", &settings)
+	for _, s := range settings {
+		if s.Name != remote {
+			continue
+		}
+		if s.PathTemplate != "" {
+			tmpl = s.PathTemplate
+		}
+		if s.Alias != "" {
+			alias = s.Alias
+		}
+	}
+	return tmpl, alias
+}
+
+// repoPath returns where the repo is cloned by executing its path
+// template. Relative paths are in the repo_path, while paths starting
+// with / or ~ are used as they are
+func repoPath(r storage.Repository) (string, error) {
+	data := newPathTemplateData(r)
+	text, alias := pathTemplate(data.Remote)
+	data.Alias = alias
+
+	tmpl, err := template.New("path_template").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("invalid path_template %q\n%s", text, err)
+	}
+	out := &bytes.Buffer{}
+	if err := tmpl.Execute(out, data); err != nil {
+		return "", fmt.Errorf("invalid path_template %q\n%s", text, err)
+	}
+
+	path := strings.TrimSpace(out.String())
+	if path == "" {
+		return "", fmt.Errorf("%w for %s", errEmptyRepoPath, r.Name)
+	}
+	if strings.HasPrefix(path, "~") {
+		return homedir.Expand(filepath.Clean(path))
+	}
+	if filepath.IsAbs(path) {
+		return filepath.Clean(path), nil
+	}
+	return filepath.Join(viper.GetString("repo_path"), path), nil
+}
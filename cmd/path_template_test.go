@@ -0,0 +1,150 @@
+package cmd
+
+import (
+	"bytes"
+	"io/ioutil"
+	"testing"
+
+	"github.com/TheHipbot/hermes/pkg/fs"
+	"github.com/TheHipbot/hermes/pkg/storage"
+	homedir "github.com/mitchellh/go-homedir"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/suite"
+	"gopkg.in/src-d/go-billy.v4/memfs"
+	"gopkg.in/src-d/go-billy.v4/util"
+)
+
+type PathTemplateSuite struct {
+	suite.Suite
+}
+
+func (suite *PathTemplateSuite) SetupTest() {
+	configFS = &fs.ConfigFS{
+		FS: memfs.New(),
+	}
+	configFS.Setup()
+	appFs = memfs.New()
+	store = storage.NewFileStorage(configFS.FS, configFS.CachePath())
+	viper.Set("repo_path", "/repos/")
+	viper.SetConfigType("yaml")
+	suite.Nil(viper.ReadConfig(bytes.NewBufferString(`
+remotes:
+- name: gitlab.corp.com
+  alias: work
+  path_template: "~/work/{{.Alias}}/{{.Repo}}"
+- name: github.com
+  path_template: "{{if eq .Language \"Go\"}}go/src/{{.Name}}{{else}}{{.Repo}}{{end}}"
+`)))
+}
+
+func (suite *PathTemplateSuite) TearDownTest() {
+	viper.ReadConfig(bytes.NewBufferString(""))
+}
+
+func (suite *PathTemplateSuite) readFile(path string) (string, error) {
+	f, err := appFs.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	content, err := ioutil.ReadAll(f)
+	return string(content), err
+}
+
+func (suite *PathTemplateSuite) TestRepoPath() {
+	path, err := repoPath(storage.Repository{Name: "bitbucket.org/TheHipbot/weather"})
+	suite.Nil(err)
+	suite.Equal("/repos/bitbucket.org/TheHipbot/weather", path, "The default template should use the full name")
+
+	path, err = repoPath(storage.Repository{Name: "gitlab.corp.com/payments/core/ledger"})
+	suite.Nil(err)
+	home, _ := homedir.Dir()
+	suite.Equal(home+"/work/work/ledger", path, "Paths starting with ~ should be in the home directory")
+
+	path, err = repoPath(storage.Repository{Name: "github.com/TheHipbot/hermes", Language: "Go"})
+	suite.Nil(err)
+	suite.Equal("/repos/go/src/github.com/TheHipbot/hermes", path)
+
+	path, err = repoPath(storage.Repository{Name: "github.com/TheHipbot/dotfiles", Language: "Shell"})
+	suite.Nil(err)
+	suite.Equal("/repos/dotfiles", path)
+
+	viper.Set("path_template", "/src/{{.Owner}}/{{.Repo}}")
+	defer viper.Set("path_template", "{{.Name}}")
+	path, err = repoPath(storage.Repository{Name: "gitlab.com/group/subgroup/project"})
+	suite.Nil(err)
+	suite.Equal("/src/group/subgroup/project", path, "Absolute paths should be used as they are")
+
+	viper.Set("path_template", "{{.Owner")
+	_, err = repoPath(storage.Repository{Name: "gitlab.com/group/project"})
+	suite.NotNil(err, "An invalid template should error")
+
+	viper.Set("path_template", "{{.Missing}}")
+	_, err = repoPath(storage.Repository{Name: "gitlab.com/group/project"})
+	suite.NotNil(err, "An unknown field should error")
+
+	viper.Set("path_template", "  ")
+	_, err = repoPath(storage.Repository{Name: "gitlab.com/group/project"})
+	suite.NotNil(err, "An empty path should error")
+}
+
+func (suite *PathTemplateSuite) TestRelocate() {
+	suite.Nil(store.Open())
+	for _, r := range []storage.Repository{
+		{Name: "github.com/TheHipbot/hermes", Path: "/repos/github.com/TheHipbot/hermes", Language: "Go"},
+		{Name: "github.com/TheHipbot/dotfiles", Path: "/repos/github.com/TheHipbot/dotfiles"},
+		{Name: "github.com/TheHipbot/weather", Path: "/repos/"},
+		{Name: "bitbucket.org/TheHipbot/notes", Path: "/repos/bitbucket.org/TheHipbot/notes"},
+	} {
+		r := r
+		suite.Nil(store.AddRepository(&r))
+	}
+	suite.Nil(store.Save())
+	suite.Nil(store.Close())
+	suite.Nil(util.WriteFile(appFs, "/repos/github.com/TheHipbot/hermes/README.md", []byte("hermes"), 0644))
+	suite.Nil(util.WriteFile(appFs, "/repos/github.com/TheHipbot/weather/README.md", []byte("weather"), 0644))
+
+	cmd := &cobra.Command{}
+	cmd.Flags().Bool("dry-run", true, "")
+	repoRelocateCommand.Run(cmd, []string{})
+	_, err := appFs.Stat("/repos/github.com/TheHipbot/hermes")
+	suite.Nil(err, "A dry run should not move repos")
+
+	cmd.Flags().Set("dry-run", "false")
+	repoRelocateCommand.Run(cmd, []string{})
+
+	content, err := suite.readFile("/repos/go/src/github.com/TheHipbot/hermes/README.md")
+	suite.Nil(err, "The clone should be moved")
+	suite.Equal("hermes", content)
+	content, err = suite.readFile("/repos/weather/README.md")
+	suite.Nil(err, "A repo cached with the repo_path as its path should be moved from its name")
+	suite.Equal("weather", content)
+	_, err = appFs.Stat("/repos/github.com")
+	suite.NotNil(err, "Empty directories should be removed")
+
+	suite.Nil(store.Open())
+	defer store.Close()
+	for name, path := range map[string]string{
+		"github.com/TheHipbot/hermes":   "/repos/go/src/github.com/TheHipbot/hermes",
+		"github.com/TheHipbot/dotfiles": "/repos/dotfiles",
+		"github.com/TheHipbot/weather":  "/repos/weather",
+		"bitbucket.org/TheHipbot/notes": "/repos/bitbucket.org/TheHipbot/notes",
+	} {
+		r, _ := store.GetRepository(name)
+		suite.Equal(path, r.Path, "Cached paths should be updated")
+	}
+}
+
+func (suite *PathTemplateSuite) TestRelocateExistingPath() {
+	suite.Nil(util.WriteFile(appFs, "/repos/github.com/TheHipbot/hermes/README.md", []byte("hermes"), 0644))
+	suite.Nil(util.WriteFile(appFs, "/repos/hermes/README.md", []byte("other"), 0644))
+	suite.Equal(errRelocateExists, relocateRepo("/repos/github.com/TheHipbot/hermes", "/repos/hermes"))
+
+	content, _ := suite.readFile("/repos/hermes/README.md")
+	suite.Equal("other", content, "An existing directory should not be replaced")
+}
+
+func TestPathTemplateSuite(t *testing.T) {
+	suite.Run(t, new(PathTemplateSuite))
+}
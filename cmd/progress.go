@@ -0,0 +1,175 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/TheHipbot/hermes/pkg/repo"
+	"github.com/spf13/cobra"
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+const (
+	progressQuiet = iota
+	progressBar
+	progressJSON
+
+	progressBarWidth = 20
+)
+
+// terminalWidth returns the width of the terminal f is,
+// and false when it is not a terminal
+var terminalWidth = func(f *os.File) (int, bool) {
+	fd := int(f.Fd())
+	if !terminal.IsTerminal(fd) {
+		return 0, false
+	}
+	width, _, err := terminal.GetSize(fd)
+	if err != nil || width <= 0 {
+		return 80, true
+	}
+	return width, true
+}
+
+// cloneProgress shows the progress of clones on stderr, so stdout stays
+// clean for the alias. On a terminal it is a single updating bar, with
+// --json every event is a json line, and otherwise only git's messages
+// are shown
+type cloneProgress struct {
+	mu       sync.Mutex
+	out      io.Writer
+	mode     int
+	width    int
+	barShown bool
+}
+
+// progressEvent is a line written with --json
+type progressEvent struct {
+	Repo string `json:"repo"`
+	repo.ProgressEvent
+}
+
+// repoProgress reports the progress of the clone of one repo
+type repoProgress struct {
+	progress *cloneProgress
+	name     string
+}
+
+func (r *repoProgress) Progress(event repo.ProgressEvent) {
+	r.progress.show(r.name, event)
+}
+
+// newCloneProgress creates the progress shown for the command's clones
+func newCloneProgress(cmd *cobra.Command) *cloneProgress {
+	p := &cloneProgress{
+		out: os.Stderr,
+	}
+	if jsonOutput, _ := cmd.Flags().GetBool("json"); jsonOutput {
+		p.mode = progressJSON
+	} else if width, ok := terminalWidth(os.Stderr); ok {
+		p.mode = progressBar
+		p.width = width
+	}
+	return p
+}
+
+// reporter returns the reporter for the clone of the named repo
+func (p *cloneProgress) reporter(name string) repo.ProgressReporter {
+	return &repoProgress{
+		progress: p,
+		name:     name,
+	}
+}
+
+func (p *cloneProgress) show(name string, event repo.ProgressEvent) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	switch {
+	case p.mode == progressJSON:
+		line, _ := json.Marshal(progressEvent{Repo: name, ProgressEvent: event})
+		fmt.Fprintf(p.out, "%s\n", line)
+	case event.Message != "":
+		p.clearBar()
+		fmt.Fprintf(p.out, "%s: %s\n", name, event.Message)
+	case p.mode == progressBar:
+		line := progressLine(name, event)
+		if len(line) >= p.width {
+			line = line[:p.width-1]
+		}
+		fmt.Fprintf(p.out, "\r\033[K%s", line)
+		p.barShown = true
+	}
+}
+
+// clear removes the bar once clones are done
+func (p *cloneProgress) clear() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.clearBar()
+}
+
+func (p *cloneProgress) clearBar() {
+	if p.barShown {
+		fmt.Fprint(p.out, "\r\033[K")
+		p.barShown = false
+	}
+}
+
+// writer returns a writer to w which clears the bar before each write,
+// so lines printed while repos are cloned do not run into it
+func (p *cloneProgress) writer(w io.Writer) io.Writer {
+	return &clearingWriter{
+		progress: p,
+		out:      w,
+	}
+}
+
+type clearingWriter struct {
+	progress *cloneProgress
+	out      io.Writer
+}
+
+func (w *clearingWriter) Write(b []byte) (int, error) {
+	w.progress.mu.Lock()
+	defer w.progress.mu.Unlock()
+	w.progress.clearBar()
+	return w.out.Write(b)
+}
+
+// progressLine is the bar for the event, such as
+// hermes Receiving objects [=========>          ]  45% (450/1000) 1.2 MiB
+func progressLine(name string, event repo.ProgressEvent) string {
+	if event.Total == 0 {
+		return fmt.Sprintf("%s %s %d", name, event.Phase, event.Objects)
+	}
+	filled := event.Percent * progressBarWidth / 100
+	bar := strings.Repeat("=", filled)
+	if filled < progressBarWidth {
+		bar += ">" + strings.Repeat(" ", progressBarWidth-filled-1)
+	}
+	line := fmt.Sprintf("%s %s [%s] %3d%% (%d/%d)", name, event.Phase, bar, event.Percent, event.Objects, event.Total)
+	if event.Bytes > 0 {
+		line += " " + formatBytes(event.Bytes)
+	}
+	return line
+}
+
+// formatBytes formats the size like git does, such as 1.2 MiB
+func formatBytes(size int64) string {
+	units := []string{"bytes", "KiB", "MiB", "GiB"}
+	value := float64(size)
+	i := 0
+	for value >= 1024 && i < len(units)-1 {
+		value /= 1024
+		i++
+	}
+	if i == 0 {
+		return fmt.Sprintf("%d bytes", size)
+	}
+	return fmt.Sprintf("%.1f %s", value, units[i])
+}
@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/TheHipbot/hermes/pkg/repo"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/suite"
+)
+
+type ProgressSuite struct {
+	suite.Suite
+	out           *bytes.Buffer
+	terminalWidth func(*os.File) (int, bool)
+}
+
+var (
+	receivingEvent = repo.ProgressEvent{
+		Phase:   "Receiving objects",
+		Percent: 45,
+		Objects: 450,
+		Total:   1000,
+		Bytes:   1258291,
+	}
+	messageEvent = repo.ProgressEvent{
+		Message: "warning: remote HEAD refers to nonexistent ref",
+	}
+)
+
+func (suite *ProgressSuite) SetupTest() {
+	suite.out = &bytes.Buffer{}
+	suite.terminalWidth = terminalWidth
+}
+
+func (suite *ProgressSuite) TearDownTest() {
+	terminalWidth = suite.terminalWidth
+}
+
+func (suite *ProgressSuite) newProgress(jsonOutput, tty bool) *cloneProgress {
+	terminalWidth = func(*os.File) (int, bool) {
+		return 100, tty
+	}
+	cmd := &cobra.Command{}
+	addCloneFlags(cmd)
+	if jsonOutput {
+		cmd.Flags().Set("json", "true")
+	}
+	p := newCloneProgress(cmd)
+	p.out = suite.out
+	return p
+}
+
+func (suite *ProgressSuite) TestBar() {
+	p := suite.newProgress(false, true)
+	r := p.reporter("github.com/TheHipbot/hermes")
+	r.Progress(repo.ProgressEvent{Phase: "Enumerating objects", Objects: 12})
+	r.Progress(receivingEvent)
+	suite.Equal("\r\033[Kgithub.com/TheHipbot/hermes Enumerating objects 12"+
+		"\r\033[Kgithub.com/TheHipbot/hermes Receiving objects [=========>          ]  45% (450/1000) 1.2 MiB",
+		suite.out.String(), "The bar should be rewritten on one line")
+
+	suite.out.Reset()
+	r.Progress(messageEvent)
+	suite.Equal("\r\033[Kgithub.com/TheHipbot/hermes: warning: remote HEAD refers to nonexistent ref\n", suite.out.String(),
+		"The bar should be cleared before messages")
+
+	suite.out.Reset()
+	r.Progress(receivingEvent)
+	stdout := &bytes.Buffer{}
+	p.writer(stdout).Write([]byte("[1/1] cloned github.com/TheHipbot/hermes\n"))
+	suite.True(bytes.HasSuffix(suite.out.Bytes(), []byte("\r\033[K")), "The bar should be cleared before other output")
+	suite.Equal("[1/1] cloned github.com/TheHipbot/hermes\n", stdout.String())
+
+	suite.out.Reset()
+	p.clear()
+	suite.Empty(suite.out.String(), "A cleared bar should not be cleared again")
+}
+
+func (suite *ProgressSuite) TestBarWidth() {
+	p := suite.newProgress(false, true)
+	p.width = 40
+	p.reporter("github.com/TheHipbot/hermes").Progress(receivingEvent)
+	suite.Equal("\r\033[Kgithub.com/TheHipbot/hermes Receiving o", suite.out.String(), "The bar should fit the terminal")
+}
+
+func (suite *ProgressSuite) TestQuiet() {
+	p := suite.newProgress(false, false)
+	r := p.reporter("github.com/TheHipbot/hermes")
+	r.Progress(receivingEvent)
+	suite.Empty(suite.out.String(), "Progress should not be shown when stderr is not a terminal")
+
+	r.Progress(messageEvent)
+	suite.Equal("github.com/TheHipbot/hermes: warning: remote HEAD refers to nonexistent ref\n", suite.out.String())
+}
+
+func (suite *ProgressSuite) TestJSON() {
+	p := suite.newProgress(true, true)
+	r := p.reporter("github.com/TheHipbot/hermes")
+	r.Progress(receivingEvent)
+	r.Progress(repo.ProgressEvent{Phase: "Resolving deltas", Percent: 100, Objects: 12, Total: 12, Done: true})
+	r.Progress(messageEvent)
+	suite.Equal(`{"repo":"github.com/TheHipbot/hermes","phase":"Receiving objects","percent":45,"objects":450,"total":1000,"bytes":1258291}
+{"repo":"github.com/TheHipbot/hermes","phase":"Resolving deltas","percent":100,"objects":12,"total":12,"done":true}
+{"repo":"github.com/TheHipbot/hermes","percent":0,"objects":0,"message":"warning: remote HEAD refers to nonexistent ref"}
+`, suite.out.String())
+}
+
+func (suite *ProgressSuite) TestFormatBytes() {
+	suite.Equal("512 bytes", formatBytes(512))
+	suite.Equal("1.5 KiB", formatBytes(1536))
+	suite.Equal("2.0 GiB", formatBytes(2<<30))
+}
+
+func TestProgressSuite(t *testing.T) {
+	suite.Run(t, new(ProgressSuite))
+}
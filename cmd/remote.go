@@ -10,9 +10,9 @@ import (
 
 	"github.com/TheHipbot/hermes/pkg/prompt"
 	"github.com/TheHipbot/hermes/pkg/remote"
+	"github.com/TheHipbot/hermes/pkg/repo"
 	"github.com/TheHipbot/hermes/pkg/storage"
 	"github.com/spf13/cobra"
-	"github.com/spf13/viper"
 )
 
 type driver struct {
@@ -43,11 +43,17 @@ var (
 
 func init() {
 	remoteCmd.AddCommand(remoteAddCmd)
+	remoteCmd.AddCommand(remoteEditCmd)
 	remoteCmd.AddCommand(remoteRefreshCmd)
 	remoteCmd.Flags().BoolVarP(&getAllReposFlg, "all", "a", false, "get all repos")
 	remoteCmd.Flags().StringVarP(&protocolFlg, "protocol", "p", "", "protocol to use for repos of given remote(s)")
 	remoteAddCmd.Flags().StringVarP(&remoteTypeFlg, "type", "t", "", "remote type (e.g. github, gitlab, etc.)")
 	remoteAddCmd.Flags().StringVar(&tokenFlg, "token", "", "auth token")
+	remoteEditCmd.Flags().StringArray("git-config", []string{}, "set git config in the remote's repos, as section.name=value")
+	remoteEditCmd.Flags().StringArray("unset-git-config", []string{}, "stop setting the git config key in the remote's repos")
+
+	remoteCmd.RegisterFlagCompletionFunc("protocol", completeProtocols)
+	remoteAddCmd.RegisterFlagCompletionFunc("type", completeDriverTypes)
 }
 
 // remoteCmd represents the base remote command when called without any subcommands
@@ -62,10 +68,11 @@ var remoteCmd = &cobra.Command{
 
 // remoteAddCmd represents the base command when called without any subcommands
 var remoteAddCmd = &cobra.Command{
-	Use:   "add",
-	Short: "Add remotes and authentication for hermes repositories",
-	Args:  cobra.MinimumNArgs(1),
-	Run:   remoteAddHandler,
+	Use:               "add",
+	Short:             "Add remotes and authentication for hermes repositories",
+	Args:              cobra.MinimumNArgs(1),
+	ValidArgsFunction: completeRemoteURLs,
+	Run:               remoteAddHandler,
 }
 
 func promptAndGetAuth(remoteURL *url.URL) (remote.Auth, error) {
@@ -99,6 +106,15 @@ func promptAndGetAuth(remoteURL *url.URL) (remote.Auth, error) {
 	return auth, nil
 }
 
+// inputError keeps errors from non-interactive prompts so callers
+// can report them, all other prompt errors become errInput
+func inputError(err error) error {
+	if prompt.IsNonInteractive(err) {
+		return err
+	}
+	return errInput
+}
+
 func getProtocolIndex() (int, error) {
 	protocolIndex := -1
 	var err error
@@ -118,7 +134,7 @@ func getProtocolIndex() (int, error) {
 		p := prompt.CreateProtocolSelectPrompt(prompter, protocols)
 		protocolIndex, _, err = p.Run()
 		if err != nil {
-			return protocolIndex, errInput
+			return protocolIndex, inputError(err)
 		}
 	}
 
@@ -126,15 +142,18 @@ func getProtocolIndex() (int, error) {
 }
 
 func remoteAddHandler(cmd *cobra.Command, args []string) {
-	store.Open()
+	openStore()
 	defer store.Close()
-	defer store.Save()
+	defer saveStore()
 	defer credentialsStorer.Close()
 	if err := addReposFromRemote(args[0]); err != nil {
 		fmt.Println(err)
-		store.Save()
+		saveStore()
 		store.Close()
 		credentialsStorer.Close()
+		if prompt.IsNonInteractive(err) {
+			os.Exit(ExitInputRequired)
+		}
 		os.Exit(1)
 	}
 }
@@ -157,7 +176,7 @@ func addReposFromRemote(remoteStr string) error {
 			p := prompt.CreateDriverSelectPrompt(prompter, drivers)
 			i, _, err := p.Run()
 			if err != nil {
-				return errInput
+				return inputError(err)
 			}
 			remoteType = drivers[i].Name
 		}
@@ -168,7 +187,7 @@ func addReposFromRemote(remoteStr string) error {
 
 	auth, err := promptAndGetAuth(remoteURL)
 	if err != nil {
-		return errInput
+		return inputError(err)
 	}
 	driver, err := remote.NewDriver(remoteType, &remote.DriverOpts{
 		AllRepos: getAllReposFlg,
@@ -177,47 +196,145 @@ func addReposFromRemote(remoteStr string) error {
 	})
 	driver.SetHost(remoteURL.String())
 	driver.Authenticate(auth)
-	repos, err := driver.GetRepos()
+	// the remote is only set up once its first page of repos has
+	// been retrieved, so a remote which can't be reached is never added
+	remoteReady := false
+	setupRemote := func() error {
+		if remoteReady {
+			return nil
+		}
+		remoteReady = true
+		if !remoteCached {
+			protocolIndex, err := getProtocolIndex()
+			if err != nil {
+				return err
+			}
+
+			store.AddRemote(remoteURL.String(), remoteName, remoteType, protocols[protocolIndex])
+		} else if protocolFlg != "" {
+			protocolIndex, err := getProtocolIndex()
+			if err != nil {
+				return err
+			}
+			cachedRemote.Protocol = protocols[protocolIndex]
+		}
+		return nil
+	}
+
+	// repos are added to the cache a page at a time as they are retrieved
+	var setupErr error
+	addPage := func(page []remote.Repo) error {
+		if setupErr = setupRemote(); setupErr != nil {
+			return setupErr
+		}
+		for _, r := range page {
+			if err := addRepositoryFromRemote(r); err != nil {
+				setupErr = err
+				return err
+			}
+		}
+		return nil
+	}
+
+	err = driver.StreamRepos(addPage)
 	for err == remote.ErrAuth {
 		fmt.Println("Authentication error received from remote")
 		credentialsStorer.Delete(remoteName)
 		auth, err = promptAndGetAuth(remoteURL)
 		if err == nil {
 			driver.Authenticate(auth)
-			repos, err = driver.GetRepos()
+			err = driver.StreamRepos(addPage)
 		}
 	}
 
-	if err != nil {
+	if setupErr != nil {
+		return setupErr
+	} else if prompt.IsNonInteractive(err) {
+		return err
+	} else if err != nil {
 		return errRetrievingRepos
 	}
 
-	if !remoteCached {
-		protocolIndex, err := getProtocolIndex()
-		if err != nil {
-			return err
+	return setupRemote()
+}
+
+// addRepositoryFromRemote adds a repo returned by a driver to the cache,
+// repos already cached keep their path and tags but have their details
+// refreshed
+func addRepositoryFromRemote(r remote.Repo) error {
+	if r.Name == "" {
+		return nil
+	}
+	repoToAdd := &storage.Repository{
+		Name:          r.Name,
+		CloneURL:      r.CloneURL,
+		SSHURL:        r.SSHURL,
+		WebURL:        r.URL,
+		Description:   r.Description,
+		DefaultBranch: r.DefaultBranch,
+		Language:      r.Language,
+		Topics:        r.Topics,
+		Visibility:    r.Visibility,
+		Archived:      r.Archived,
+		Stars:         r.Stars,
+		LastActivity:  r.LastActivity,
+	}
+	path, err := repoPath(*repoToAdd)
+	if err != nil {
+		return err
+	}
+	repoToAdd.Path = path
+	if err := store.AddRepository(repoToAdd); errors.Is(err, storage.ErrRepoExists) {
+		if cached, ok := store.GetRepository(repoToAdd.Name); ok {
+			repoToAdd.Path = cached.Path
+			repoToAdd.Tags = cached.Tags
+			store.UpdateRepository(repoToAdd)
 		}
+	}
+	return nil
+}
+
+var remoteEditCmd = &cobra.Command{
+	Use:               "edit [remote]",
+	Short:             "Edit the git config set in the local config of a remote's repos when they are cloned",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeRemoteNames,
+	Run:               remoteEditHandler,
+}
+
+func remoteEditHandler(cmd *cobra.Command, args []string) {
+	set, _ := cmd.Flags().GetStringArray("git-config")
+	unset, _ := cmd.Flags().GetStringArray("unset-git-config")
 
-		store.AddRemote(remoteURL.String(), remoteName, remoteType, protocols[protocolIndex])
-	} else if protocolFlg != "" {
-		protocolIndex, err := getProtocolIndex()
+	openStore()
+	defer store.Close()
+	r, ok := store.SearchRemote(args[0])
+	if !ok {
+		fmt.Printf("no remote %s found\n", args[0])
+		os.Exit(1)
+	}
+
+	for _, entry := range set {
+		key, value, err := parseGitConfigEntry(entry)
 		if err != nil {
-			return err
+			fmt.Printf("invalid git config %q, %s\n", entry, err)
+			os.Exit(ExitInvalidArguments)
+		}
+		if r.GitConfig == nil {
+			r.GitConfig = map[string]string{}
 		}
-		cachedRemote.Protocol = protocols[protocolIndex]
+		r.GitConfig[key] = value
+	}
+	for _, key := range unset {
+		delete(r.GitConfig, key)
+	}
+	if len(set) > 0 || len(unset) > 0 {
+		saveStore()
 	}
 
-	// add repos to cache
-	for _, r := range repos {
-		repoToAdd := &storage.Repository{
-			Name:     r["name"],
-			Path:     fmt.Sprintf("%s%s", viper.GetString("repo_path"), r["name"]),
-			CloneURL: r["clone_url"],
-			SSHURL:   r["ssh_url"],
-		}
-		store.AddRepository(repoToAdd)
+	for _, key := range repo.SortedKeys(r.GitConfig) {
+		fmt.Printf("%s=%s\n", key, r.GitConfig[key])
 	}
-	return nil
 }
 
 // remoteRefreshCmd represents the base command when called without any subcommands
@@ -228,23 +345,27 @@ var remoteRefreshCmd = &cobra.Command{
 }
 
 func remoteRefreshHandler(cmd *cobra.Command, args []string) {
-	store.Open()
+	openStore()
 	defer store.Close()
-	defer store.Save()
+	defer saveStore()
 	defer credentialsStorer.Close()
 
 	var aggErr error
+	exitStatus := 1
 	for _, r := range store.ListRemotes() {
 		fmt.Printf("refreshing %s\n", r.Name)
 		if err := addReposFromRemote(r.URL); err != nil {
 			fmt.Println(err)
 			aggErr = err
+			if prompt.IsNonInteractive(err) {
+				exitStatus = ExitInputRequired
+			}
 		}
 	}
 	if aggErr != nil {
-		store.Save()
+		saveStore()
 		store.Close()
 		credentialsStorer.Close()
-		os.Exit(1)
+		os.Exit(exitStatus)
 	}
 }
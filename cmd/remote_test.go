@@ -7,6 +7,7 @@ import (
 	"github.com/TheHipbot/hermes/pkg/credentials"
 
 	"github.com/TheHipbot/hermes/mock"
+	"github.com/TheHipbot/hermes/pkg/prompt"
 	"github.com/TheHipbot/hermes/pkg/remote"
 	"github.com/TheHipbot/hermes/pkg/storage"
 	"github.com/golang/mock/gomock"
@@ -45,6 +46,7 @@ func (suite *RemoteCmdSuite) SetupTest() {
 
 func (suite *RemoteCmdSuite) TearDownTest() {
 	suite.ctrl.Finish()
+	credentialsStorer = nil
 }
 
 func (suite *RemoteCmdSuite) TestWithTokenAuth() {
@@ -59,18 +61,18 @@ func (suite *RemoteCmdSuite) TestWithTokenAuth() {
 	defer ctrl.Finish()
 
 	store = mockStore
-	repos := []map[string]string{
+	repos := []remote.Repo{
 		{
-			"name": "github.com/thehipbot/hermes",
-			"url":  "https://github.com/thehipbot/hermes",
+			Name: "github.com/thehipbot/hermes",
+			URL:  "https://github.com/thehipbot/hermes",
 		},
 		{
-			"name": "github.com/thehipbot/dotfiles",
-			"url":  "https://github.com/thehipbot/dotfiles",
+			Name: "github.com/thehipbot/dotfiles",
+			URL:  "https://github.com/thehipbot/dotfiles",
 		},
 		{
-			"name": "github.com/carsdotcom/bitcar",
-			"url":  "https://github.com/carsdotcom/bitcar",
+			Name: "github.com/carsdotcom/bitcar",
+			URL:  "https://github.com/carsdotcom/bitcar",
 		},
 	}
 
@@ -100,8 +102,8 @@ func (suite *RemoteCmdSuite) TestWithTokenAuth() {
 
 	suite.mockDriver.
 		EXPECT().
-		GetRepos().
-		Return(repos, nil).
+		StreamRepos(gomock.Any()).
+		DoAndReturn(streamRepos(repos)).
 		Times(1)
 
 	// Open store, add remote, add repos, save, close
@@ -109,7 +111,7 @@ func (suite *RemoteCmdSuite) TestWithTokenAuth() {
 		mockStore.
 			EXPECT().
 			Open().
-			Return().
+			Return(nil).
 			Times(1),
 
 		mockStore.
@@ -121,8 +123,9 @@ func (suite *RemoteCmdSuite) TestWithTokenAuth() {
 		mockStore.
 			EXPECT().
 			AddRepository(&storage.Repository{
-				Name: "github.com/thehipbot/hermes",
-				Path: fmt.Sprintf("%s%s", testRepoPath, "github.com/thehipbot/hermes"),
+				Name:   "github.com/thehipbot/hermes",
+				Path:   fmt.Sprintf("%s%s", testRepoPath, "github.com/thehipbot/hermes"),
+				WebURL: "https://github.com/thehipbot/hermes",
 			}).
 			Return(nil).
 			Times(1),
@@ -130,8 +133,9 @@ func (suite *RemoteCmdSuite) TestWithTokenAuth() {
 		mockStore.
 			EXPECT().
 			AddRepository(&storage.Repository{
-				Name: "github.com/thehipbot/dotfiles",
-				Path: fmt.Sprintf("%s%s", testRepoPath, "github.com/thehipbot/dotfiles"),
+				Name:   "github.com/thehipbot/dotfiles",
+				Path:   fmt.Sprintf("%s%s", testRepoPath, "github.com/thehipbot/dotfiles"),
+				WebURL: "https://github.com/thehipbot/dotfiles",
 			}).
 			Return(nil).
 			Times(1),
@@ -139,8 +143,9 @@ func (suite *RemoteCmdSuite) TestWithTokenAuth() {
 		mockStore.
 			EXPECT().
 			AddRepository(&storage.Repository{
-				Name: "github.com/carsdotcom/bitcar",
-				Path: fmt.Sprintf("%s%s", testRepoPath, "github.com/carsdotcom/bitcar"),
+				Name:   "github.com/carsdotcom/bitcar",
+				Path:   fmt.Sprintf("%s%s", testRepoPath, "github.com/carsdotcom/bitcar"),
+				WebURL: "https://github.com/carsdotcom/bitcar",
 			}).
 			Return(nil).
 			Times(1),
@@ -163,18 +168,18 @@ func (suite *RemoteCmdSuite) TestAuthError() {
 	defer ctrl.Finish()
 
 	store = mockStore
-	repos := []map[string]string{
+	repos := []remote.Repo{
 		{
-			"name": "github.com/thehipbot/hermes",
-			"url":  "https://github.com/thehipbot/hermes",
+			Name: "github.com/thehipbot/hermes",
+			URL:  "https://github.com/thehipbot/hermes",
 		},
 		{
-			"name": "github.com/thehipbot/dotfiles",
-			"url":  "https://github.com/thehipbot/dotfiles",
+			Name: "github.com/thehipbot/dotfiles",
+			URL:  "https://github.com/thehipbot/dotfiles",
 		},
 		{
-			"name": "github.com/carsdotcom/bitcar",
-			"url":  "https://github.com/carsdotcom/bitcar",
+			Name: "github.com/carsdotcom/bitcar",
+			URL:  "https://github.com/carsdotcom/bitcar",
 		},
 	}
 
@@ -193,7 +198,7 @@ func (suite *RemoteCmdSuite) TestAuthError() {
 		mockStore.
 			EXPECT().
 			Open().
-			Return().
+			Return(nil).
 			Times(1),
 
 		mockStore.
@@ -205,8 +210,9 @@ func (suite *RemoteCmdSuite) TestAuthError() {
 		mockStore.
 			EXPECT().
 			AddRepository(&storage.Repository{
-				Name: "github.com/thehipbot/hermes",
-				Path: fmt.Sprintf("%s%s", testRepoPath, "github.com/thehipbot/hermes"),
+				Name:   "github.com/thehipbot/hermes",
+				Path:   fmt.Sprintf("%s%s", testRepoPath, "github.com/thehipbot/hermes"),
+				WebURL: "https://github.com/thehipbot/hermes",
 			}).
 			Return(nil).
 			Times(1),
@@ -214,8 +220,9 @@ func (suite *RemoteCmdSuite) TestAuthError() {
 		mockStore.
 			EXPECT().
 			AddRepository(&storage.Repository{
-				Name: "github.com/thehipbot/dotfiles",
-				Path: fmt.Sprintf("%s%s", testRepoPath, "github.com/thehipbot/dotfiles"),
+				Name:   "github.com/thehipbot/dotfiles",
+				Path:   fmt.Sprintf("%s%s", testRepoPath, "github.com/thehipbot/dotfiles"),
+				WebURL: "https://github.com/thehipbot/dotfiles",
 			}).
 			Return(nil).
 			Times(1),
@@ -223,8 +230,9 @@ func (suite *RemoteCmdSuite) TestAuthError() {
 		mockStore.
 			EXPECT().
 			AddRepository(&storage.Repository{
-				Name: "github.com/carsdotcom/bitcar",
-				Path: fmt.Sprintf("%s%s", testRepoPath, "github.com/carsdotcom/bitcar"),
+				Name:   "github.com/carsdotcom/bitcar",
+				Path:   fmt.Sprintf("%s%s", testRepoPath, "github.com/carsdotcom/bitcar"),
+				WebURL: "https://github.com/carsdotcom/bitcar",
 			}).
 			Return(nil).
 			Times(1),
@@ -244,11 +252,11 @@ func (suite *RemoteCmdSuite) TestAuthError() {
 		Return(nil).
 		Times(1)
 
-	// return auth error from GetRepos
+	// return auth error from StreamRepos
 	suite.mockDriver.
 		EXPECT().
-		GetRepos().
-		Return(nil, remote.ErrAuth).
+		StreamRepos(gomock.Any()).
+		Return(remote.ErrAuth).
 		Times(1)
 
 	gomock.InOrder(
@@ -294,8 +302,8 @@ func (suite *RemoteCmdSuite) TestAuthError() {
 
 	suite.mockDriver.
 		EXPECT().
-		GetRepos().
-		Return(repos, nil).
+		StreamRepos(gomock.Any()).
+		DoAndReturn(streamRepos(repos)).
 		Times(1)
 
 	promptForProtocol(mockPrompter, mockSelectPrompt, 0, "https")
@@ -314,18 +322,18 @@ func (suite *RemoteCmdSuite) TestWithStoredTokenAuth() {
 	defer ctrl.Finish()
 
 	store = mockStore
-	repos := []map[string]string{
+	repos := []remote.Repo{
 		{
-			"name": "github.com/thehipbot/hermes",
-			"url":  "https://github.com/thehipbot/hermes",
+			Name: "github.com/thehipbot/hermes",
+			URL:  "https://github.com/thehipbot/hermes",
 		},
 		{
-			"name": "github.com/thehipbot/dotfiles",
-			"url":  "https://github.com/thehipbot/dotfiles",
+			Name: "github.com/thehipbot/dotfiles",
+			URL:  "https://github.com/thehipbot/dotfiles",
 		},
 		{
-			"name": "github.com/carsdotcom/bitcar",
-			"url":  "https://github.com/carsdotcom/bitcar",
+			Name: "github.com/carsdotcom/bitcar",
+			URL:  "https://github.com/carsdotcom/bitcar",
 		},
 	}
 
@@ -369,8 +377,8 @@ func (suite *RemoteCmdSuite) TestWithStoredTokenAuth() {
 
 	suite.mockDriver.
 		EXPECT().
-		GetRepos().
-		Return(repos, nil).
+		StreamRepos(gomock.Any()).
+		DoAndReturn(streamRepos(repos)).
 		Times(1)
 
 	// Open store, add remote, add repos, save, close
@@ -378,7 +386,7 @@ func (suite *RemoteCmdSuite) TestWithStoredTokenAuth() {
 		mockStore.
 			EXPECT().
 			Open().
-			Return().
+			Return(nil).
 			Times(1),
 
 		mockStore.
@@ -390,8 +398,9 @@ func (suite *RemoteCmdSuite) TestWithStoredTokenAuth() {
 		mockStore.
 			EXPECT().
 			AddRepository(&storage.Repository{
-				Name: "github.com/thehipbot/hermes",
-				Path: fmt.Sprintf("%s%s", testRepoPath, "github.com/thehipbot/hermes"),
+				Name:   "github.com/thehipbot/hermes",
+				Path:   fmt.Sprintf("%s%s", testRepoPath, "github.com/thehipbot/hermes"),
+				WebURL: "https://github.com/thehipbot/hermes",
 			}).
 			Return(nil).
 			Times(1),
@@ -399,8 +408,9 @@ func (suite *RemoteCmdSuite) TestWithStoredTokenAuth() {
 		mockStore.
 			EXPECT().
 			AddRepository(&storage.Repository{
-				Name: "github.com/thehipbot/dotfiles",
-				Path: fmt.Sprintf("%s%s", testRepoPath, "github.com/thehipbot/dotfiles"),
+				Name:   "github.com/thehipbot/dotfiles",
+				Path:   fmt.Sprintf("%s%s", testRepoPath, "github.com/thehipbot/dotfiles"),
+				WebURL: "https://github.com/thehipbot/dotfiles",
 			}).
 			Return(nil).
 			Times(1),
@@ -408,8 +418,9 @@ func (suite *RemoteCmdSuite) TestWithStoredTokenAuth() {
 		mockStore.
 			EXPECT().
 			AddRepository(&storage.Repository{
-				Name: "github.com/carsdotcom/bitcar",
-				Path: fmt.Sprintf("%s%s", testRepoPath, "github.com/carsdotcom/bitcar"),
+				Name:   "github.com/carsdotcom/bitcar",
+				Path:   fmt.Sprintf("%s%s", testRepoPath, "github.com/carsdotcom/bitcar"),
+				WebURL: "https://github.com/carsdotcom/bitcar",
 			}).
 			Return(nil).
 			Times(1),
@@ -432,25 +443,25 @@ func (suite *RemoteCmdSuite) TestRemoteAddWithAll() {
 	defer ctrl.Finish()
 
 	store = mockStore
-	repos := []map[string]string{
+	repos := []remote.Repo{
 		{
-			"name": "github.com/thehipbot/hermes",
-			"url":  "https://github.com/thehipbot/hermes",
+			Name: "github.com/thehipbot/hermes",
+			URL:  "https://github.com/thehipbot/hermes",
 		},
 		{
-			"name": "github.com/thehipbot/dotfiles",
-			"url":  "https://github.com/thehipbot/dotfiles",
+			Name: "github.com/thehipbot/dotfiles",
+			URL:  "https://github.com/thehipbot/dotfiles",
 		},
 		{
-			"name": "github.com/carsdotcom/bitcar",
-			"url":  "https://github.com/carsdotcom/bitcar",
+			Name: "github.com/carsdotcom/bitcar",
+			URL:  "https://github.com/carsdotcom/bitcar",
 		},
 	}
 
 	mockStore.
 		EXPECT().
 		Open().
-		Return().
+		Return(nil).
 		Times(1)
 
 	mockStore.
@@ -474,8 +485,8 @@ func (suite *RemoteCmdSuite) TestRemoteAddWithAll() {
 	gomock.InOrder(
 		suite.mockDriver.
 			EXPECT().
-			GetRepos().
-			Return(repos, nil).
+			StreamRepos(gomock.Any()).
+			DoAndReturn(streamRepos(repos)).
 			Times(1),
 
 		mockStore.
@@ -496,8 +507,9 @@ func (suite *RemoteCmdSuite) TestRemoteAddWithAll() {
 	mockStore.
 		EXPECT().
 		AddRepository(&storage.Repository{
-			Name: "github.com/thehipbot/hermes",
-			Path: fmt.Sprintf("%s%s", testRepoPath, "github.com/thehipbot/hermes"),
+			Name:   "github.com/thehipbot/hermes",
+			Path:   fmt.Sprintf("%s%s", testRepoPath, "github.com/thehipbot/hermes"),
+			WebURL: "https://github.com/thehipbot/hermes",
 		}).
 		Return(nil).
 		Times(1)
@@ -505,8 +517,9 @@ func (suite *RemoteCmdSuite) TestRemoteAddWithAll() {
 	mockStore.
 		EXPECT().
 		AddRepository(&storage.Repository{
-			Name: "github.com/thehipbot/dotfiles",
-			Path: fmt.Sprintf("%s%s", testRepoPath, "github.com/thehipbot/dotfiles"),
+			Name:   "github.com/thehipbot/dotfiles",
+			Path:   fmt.Sprintf("%s%s", testRepoPath, "github.com/thehipbot/dotfiles"),
+			WebURL: "https://github.com/thehipbot/dotfiles",
 		}).
 		Return(nil).
 		Times(1)
@@ -514,8 +527,9 @@ func (suite *RemoteCmdSuite) TestRemoteAddWithAll() {
 	mockStore.
 		EXPECT().
 		AddRepository(&storage.Repository{
-			Name: "github.com/carsdotcom/bitcar",
-			Path: fmt.Sprintf("%s%s", testRepoPath, "github.com/carsdotcom/bitcar"),
+			Name:   "github.com/carsdotcom/bitcar",
+			Path:   fmt.Sprintf("%s%s", testRepoPath, "github.com/carsdotcom/bitcar"),
+			WebURL: "https://github.com/carsdotcom/bitcar",
 		}).
 		Return(nil).
 		Times(1)
@@ -539,18 +553,18 @@ func (suite *RemoteCmdSuite) TestRemoteAddSSH() {
 	defer ctrl.Finish()
 
 	store = mockStore
-	repos := []map[string]string{
+	repos := []remote.Repo{
 		{
-			"name": "github.com/thehipbot/hermes",
-			"url":  "https://github.com/thehipbot/hermes",
+			Name: "github.com/thehipbot/hermes",
+			URL:  "https://github.com/thehipbot/hermes",
 		},
 		{
-			"name": "github.com/thehipbot/dotfiles",
-			"url":  "https://github.com/thehipbot/dotfiles",
+			Name: "github.com/thehipbot/dotfiles",
+			URL:  "https://github.com/thehipbot/dotfiles",
 		},
 		{
-			"name": "github.com/carsdotcom/bitcar",
-			"url":  "https://github.com/carsdotcom/bitcar",
+			Name: "github.com/carsdotcom/bitcar",
+			URL:  "https://github.com/carsdotcom/bitcar",
 		},
 	}
 
@@ -580,8 +594,8 @@ func (suite *RemoteCmdSuite) TestRemoteAddSSH() {
 
 	suite.mockDriver.
 		EXPECT().
-		GetRepos().
-		Return(repos, nil).
+		StreamRepos(gomock.Any()).
+		DoAndReturn(streamRepos(repos)).
 		Times(1)
 
 	// Open store, add remote, add repos, save, close
@@ -589,7 +603,7 @@ func (suite *RemoteCmdSuite) TestRemoteAddSSH() {
 		mockStore.
 			EXPECT().
 			Open().
-			Return().
+			Return(nil).
 			Times(1),
 
 		mockStore.
@@ -601,8 +615,9 @@ func (suite *RemoteCmdSuite) TestRemoteAddSSH() {
 		mockStore.
 			EXPECT().
 			AddRepository(&storage.Repository{
-				Name: "github.com/thehipbot/hermes",
-				Path: fmt.Sprintf("%s%s", testRepoPath, "github.com/thehipbot/hermes"),
+				Name:   "github.com/thehipbot/hermes",
+				Path:   fmt.Sprintf("%s%s", testRepoPath, "github.com/thehipbot/hermes"),
+				WebURL: "https://github.com/thehipbot/hermes",
 			}).
 			Return(nil).
 			Times(1),
@@ -610,8 +625,9 @@ func (suite *RemoteCmdSuite) TestRemoteAddSSH() {
 		mockStore.
 			EXPECT().
 			AddRepository(&storage.Repository{
-				Name: "github.com/thehipbot/dotfiles",
-				Path: fmt.Sprintf("%s%s", testRepoPath, "github.com/thehipbot/dotfiles"),
+				Name:   "github.com/thehipbot/dotfiles",
+				Path:   fmt.Sprintf("%s%s", testRepoPath, "github.com/thehipbot/dotfiles"),
+				WebURL: "https://github.com/thehipbot/dotfiles",
 			}).
 			Return(nil).
 			Times(1),
@@ -619,8 +635,9 @@ func (suite *RemoteCmdSuite) TestRemoteAddSSH() {
 		mockStore.
 			EXPECT().
 			AddRepository(&storage.Repository{
-				Name: "github.com/carsdotcom/bitcar",
-				Path: fmt.Sprintf("%s%s", testRepoPath, "github.com/carsdotcom/bitcar"),
+				Name:   "github.com/carsdotcom/bitcar",
+				Path:   fmt.Sprintf("%s%s", testRepoPath, "github.com/carsdotcom/bitcar"),
+				WebURL: "https://github.com/carsdotcom/bitcar",
 			}).
 			Return(nil).
 			Times(1),
@@ -641,36 +658,36 @@ func (suite *RemoteCmdSuite) TestRemoteRefresh() {
 
 	store = mockStore
 
-	githubRepos := []map[string]string{
+	githubRepos := []remote.Repo{
 		{
-			"name": "github.com/thehipbot/hermes",
-			"url":  "https://github.com/thehipbot/hermes",
+			Name: "github.com/thehipbot/hermes",
+			URL:  "https://github.com/thehipbot/hermes",
 		},
 		{
-			"name": "github.com/thehipbot/dotfiles",
-			"url":  "https://github.com/thehipbot/dotfiles",
+			Name: "github.com/thehipbot/dotfiles",
+			URL:  "https://github.com/thehipbot/dotfiles",
 		},
 		{
-			"name": "github.com/carsdotcom/bitcar",
-			"url":  "https://github.com/carsdotcom/bitcar",
+			Name: "github.com/carsdotcom/bitcar",
+			URL:  "https://github.com/carsdotcom/bitcar",
 		},
 		{
-			"name": "github.com/thehipbot/harp",
-			"url":  "https://github.com/thehipbot/harp",
+			Name: "github.com/thehipbot/harp",
+			URL:  "https://github.com/thehipbot/harp",
 		},
 	}
 
-	gitlabRepos := []map[string]string{
+	gitlabRepos := []remote.Repo{
 		{
-			"name": "gitlab.com/TheHipbot/test",
-			"url":  "https://gitlab.com/TheHipbot/test",
+			Name: "gitlab.com/TheHipbot/test",
+			URL:  "https://gitlab.com/TheHipbot/test",
 		},
 	}
 
 	mockStore.
 		EXPECT().
 		Open().
-		Return().
+		Return(nil).
 		Times(1)
 
 	mockStore.
@@ -728,14 +745,14 @@ func (suite *RemoteCmdSuite) TestRemoteRefresh() {
 
 	suite.mockDriver.
 		EXPECT().
-		GetRepos().
-		Return(githubRepos, nil).
+		StreamRepos(gomock.Any()).
+		DoAndReturn(streamRepos(githubRepos)).
 		Times(1)
 
 	suite.mockDriver.
 		EXPECT().
-		GetRepos().
-		Return(gitlabRepos, nil).
+		StreamRepos(gomock.Any()).
+		DoAndReturn(streamRepos(gitlabRepos)).
 		Times(1)
 
 	getAuthFromStorer(suite.mockCredentialStorer, suite.mockDriver, "github.com")
@@ -745,8 +762,9 @@ func (suite *RemoteCmdSuite) TestRemoteRefresh() {
 		mockStore.
 			EXPECT().
 			AddRepository(&storage.Repository{
-				Name: r["name"],
-				Path: fmt.Sprintf("%s%s", testRepoPath, r["name"]),
+				Name:   r.Name,
+				Path:   fmt.Sprintf("%s%s", testRepoPath, r.Name),
+				WebURL: r.URL,
 			}).
 			Return(nil).
 			Times(1)
@@ -762,6 +780,89 @@ func (suite *RemoteCmdSuite) TestRemoteRefresh() {
 	remoteRefreshHandler(mockCmd, []string{})
 }
 
+func (suite *RemoteCmdSuite) TestRemoteRefreshUpdatesDetails() {
+	ctrl := gomock.NewController(suite.T())
+	mockStore := mock.NewMockStorage(ctrl)
+	suite.mockCredentialStorer = mock.NewMockCredentialsStorer(ctrl)
+	credentialsStorer = suite.mockCredentialStorer
+
+	defer ctrl.Finish()
+
+	store = mockStore
+
+	githubRepos := []remote.Repo{
+		{
+			Name:        "github.com/thehipbot/hermes",
+			URL:         "https://github.com/thehipbot/hermes",
+			Description: "Messenger of the Version Control Gods",
+			Language:    "Go",
+			Topics:      []string{"git", "cli"},
+			Stars:       12,
+		},
+	}
+	cachedRemote := &storage.Remote{
+		Name:     "github.com",
+		URL:      "https://github.com",
+		Protocol: "ssh",
+		Type:     "test",
+	}
+
+	mockStore.EXPECT().Open().Return(nil).Times(1)
+	mockStore.EXPECT().ListRemotes().Return([]*storage.Remote{cachedRemote}).Times(1)
+	mockStore.EXPECT().SearchRemote("github.com").Return(cachedRemote, true).Times(1)
+	suite.mockDriver.EXPECT().SetHost(gomock.Eq("https://github.com")).Return().Times(1)
+	suite.mockDriver.EXPECT().StreamRepos(gomock.Any()).DoAndReturn(streamRepos(githubRepos)).Times(1)
+	getAuthFromStorer(suite.mockCredentialStorer, suite.mockDriver, "github.com")
+
+	// an existing repo keeps its path and tags but has its details refreshed
+	gomock.InOrder(
+		mockStore.
+			EXPECT().
+			AddRepository(gomock.Any()).
+			Return(storage.ErrRepoExists).
+			Times(1),
+		mockStore.
+			EXPECT().
+			GetRepository("github.com/thehipbot/hermes").
+			Return(&storage.Repository{
+				Name: "github.com/thehipbot/hermes",
+				Path: "/src/hermes",
+				Tags: []string{"oncall"},
+			}, true).
+			Times(1),
+		mockStore.
+			EXPECT().
+			UpdateRepository(&storage.Repository{
+				Name:        "github.com/thehipbot/hermes",
+				Path:        "/src/hermes",
+				WebURL:      "https://github.com/thehipbot/hermes",
+				Description: "Messenger of the Version Control Gods",
+				Language:    "Go",
+				Topics:      []string{"git", "cli"},
+				Stars:       12,
+				Tags:        []string{"oncall"},
+			}).
+			Return(nil).
+			Times(1),
+	)
+
+	saveAndCloseStorage(mockStore)
+	suite.mockCredentialStorer.
+		EXPECT().
+		Close().
+		Return(nil).
+		Times(1)
+
+	remoteRefreshHandler(mockCmd, []string{})
+}
+
+// streamRepos returns a fake StreamRepos which sends repos as a single page
+func streamRepos(repos []remote.Repo) func(fn remote.PageFunc) error {
+	return func(fn remote.PageFunc) error {
+		return fn(repos)
+	}
+}
+
 // sets up expects on MockStorage for a save then close
 func saveAndCloseStorage(mockStorage *mock.MockStorage) {
 	gomock.InOrder(
@@ -807,6 +908,24 @@ func promptForDriverThenSetHost(
 	)
 }
 
+func (suite *RemoteCmdSuite) TestAddReposNonInteractive() {
+	ctrl := gomock.NewController(suite.T())
+	defer ctrl.Finish()
+	prompter = &prompt.NonInteractivePrompter{}
+	mockStore := mock.NewMockStorage(ctrl)
+	store = mockStore
+	remoteTypeFlg = ""
+
+	mockStore.
+		EXPECT().
+		SearchRemote("github.com").
+		Return(nil, false).
+		Times(1)
+
+	err := addReposFromRemote("https://github.com")
+	suite.True(prompt.IsNonInteractive(err), "Driver prompt error should be returned")
+}
+
 func promptForAuthThenStore(
 	mockPrompter *mock.MockFactory,
 	mockInputPrompt *mock.MockInputPrompt,
@@ -1,22 +1,37 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 
+	"github.com/TheHipbot/hermes/pkg/repo"
+	"github.com/TheHipbot/hermes/pkg/storage"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
 
 var (
 	hardRmFlg bool
+
+	errInvalidTag     = errors.New("tags cannot contain spaces or colons")
+	errRelocateExists = errors.New("a file or directory already exists at the new path")
 )
 
 func init() {
+	repoCmd.AddCommand(repoApplyConfigCommand)
+	repoCmd.AddCommand(repoListCommand)
+	repoCmd.AddCommand(repoRelocateCommand)
 	repoCmd.AddCommand(repoRmCommand)
+	repoCmd.AddCommand(repoTagCommand)
+	repoCmd.AddCommand(repoUntagCommand)
 
 	repoRmCommand.Flags().BoolVar(&hardRmFlg, "hard", false, "remove repo from disk")
+	repoListCommand.Flags().StringSlice("tag", []string{}, "only list repos with every given tag")
+	repoListCommand.RegisterFlagCompletionFunc("tag", completeTags)
+	repoRelocateCommand.Flags().Bool("dry-run", false, "print where repos would be moved without moving them")
 }
 
 // repoCmd represents the base remote command when called without any subcommands
@@ -31,14 +46,15 @@ var repoCmd = &cobra.Command{
 }
 
 var repoRmCommand = &cobra.Command{
-	Use:     "rm [repo name]",
-	Aliases: []string{"remove"},
-	Short:   "Remove a repo from cache and optionally from disk",
-	Run:     repoRmHandler,
+	Use:               "rm [repo name]",
+	Aliases:           []string{"remove"},
+	Short:             "Remove a repo from cache and optionally from disk",
+	ValidArgsFunction: completeRepoNames,
+	Run:               repoRmHandler,
 }
 
 func repoRmHandler(cmd *cobra.Command, args []string) {
-	store.Open()
+	openStore()
 	defer store.Close()
 	repos := store.SearchRepositories(args[0])
 	switch len(repos) {
@@ -71,6 +87,233 @@ func repoRmHandler(cmd *cobra.Command, args []string) {
 	}
 }
 
+var repoListCommand = &cobra.Command{
+	Use:     "list [search]",
+	Aliases: []string{"ls"},
+	Short:   "List repos in the cache, optionally filtered by a search or tags",
+	Run:     repoListHandler,
+}
+
+var repoTagCommand = &cobra.Command{
+	Use:               "tag [repo name] [tag...]",
+	Short:             "Add tags to a repo",
+	Args:              cobra.MinimumNArgs(2),
+	ValidArgsFunction: completeRepoThenTags,
+	Run:               repoTagHandler,
+}
+
+var repoUntagCommand = &cobra.Command{
+	Use:               "untag [repo name] [tag...]",
+	Short:             "Remove tags from a repo",
+	Args:              cobra.MinimumNArgs(2),
+	ValidArgsFunction: completeRepoThenTags,
+	Run:               repoUntagHandler,
+}
+
+var repoApplyConfigCommand = &cobra.Command{
+	Use:               "apply-config [search]",
+	Short:             "Set the git config of their remotes in repos which are already cloned",
+	ValidArgsFunction: completeRepoNames,
+	Run:               repoApplyConfigHandler,
+}
+
+func repoApplyConfigHandler(cmd *cobra.Command, args []string) {
+	openStore()
+	defer store.Close()
+
+	configured, failed := 0, 0
+	for _, r := range store.SearchRepositories(strings.Join(args, " ")) {
+		if _, err := appFs.Stat(r.Path); err != nil {
+			continue
+		}
+		gitConfig := remoteGitConfig(r.Name)
+		if len(gitConfig) == 0 {
+			continue
+		}
+		if err := gitRepository(r, "").Configure(r.Path, gitConfig); err != nil {
+			fmt.Printf("%s failed\n%s\n", r.Name, err)
+			failed++
+			continue
+		}
+		fmt.Printf("%s set %s\n", r.Name, strings.Join(repo.SortedKeys(gitConfig), ", "))
+		configured++
+	}
+
+	fmt.Printf("%d repos configured, %d failed\n", configured, failed)
+	if failed > 0 {
+		store.Close()
+		os.Exit(1)
+	}
+}
+
+var repoRelocateCommand = &cobra.Command{
+	Use:               "relocate [search]",
+	Short:             "Move clones to the paths given by the path_template and update the cache",
+	ValidArgsFunction: completeRepoNames,
+	Run:               repoRelocateHandler,
+}
+
+func repoRelocateHandler(cmd *cobra.Command, args []string) {
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	openStore()
+	defer store.Close()
+
+	relocated, failed := 0, 0
+	for _, r := range store.SearchRepositories(strings.Join(args, " ")) {
+		path, err := repoPath(r)
+		if err != nil {
+			fmt.Println(err)
+			store.Close()
+			os.Exit(1)
+		}
+		from := filepath.Clean(r.Path)
+		// get once cached new repos with the repo_path as their
+		// path, they were cloned in it by name
+		if from == filepath.Clean(viper.GetString("repo_path")) {
+			from = filepath.Join(from, r.Name)
+		}
+		if filepath.Clean(r.Path) == path {
+			continue
+		}
+
+		fmt.Printf("%s: %s -> %s\n", r.Name, from, path)
+		if !dryRun {
+			if from != path {
+				if err := relocateRepo(from, path); err != nil {
+					fmt.Printf("%s failed\n%s\n", r.Name, err)
+					failed++
+					continue
+				}
+			}
+			relocatedRepo := r
+			relocatedRepo.Path = path
+			if err := store.UpdateRepository(&relocatedRepo); err != nil {
+				fmt.Printf("%s failed\n%s\n", r.Name, err)
+				failed++
+				continue
+			}
+		}
+		relocated++
+	}
+
+	if dryRun {
+		fmt.Printf("%d repos would be relocated\n", relocated)
+		return
+	}
+	saveStore()
+	fmt.Printf("%d repos relocated, %d failed\n", relocated, failed)
+	if failed > 0 {
+		store.Close()
+		os.Exit(1)
+	}
+}
+
+// relocateRepo moves the clone at from to the path to, removing any
+// directories in the repo_path left empty. Nothing is moved when the
+// repo is not cloned
+func relocateRepo(from, to string) error {
+	if _, err := appFs.Stat(from); os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if _, err := appFs.Stat(to); err == nil {
+		return errRelocateExists
+	}
+
+	if err := appFs.MkdirAll(filepath.Dir(to), 0755); err != nil {
+		return err
+	}
+	if err := appFs.Rename(from, to); err != nil {
+		return err
+	}
+	base := viper.GetString("repo_path")
+	if strings.HasPrefix(from, base) {
+		removeEmptyDirs(filepath.Dir(from), base)
+	}
+	return nil
+}
+
+func repoListHandler(cmd *cobra.Command, args []string) {
+	tags, _ := cmd.Flags().GetStringSlice("tag")
+	query := args
+	for _, t := range tags {
+		query = append(query, fmt.Sprintf("tag:%s", t))
+	}
+
+	openStore()
+	defer store.Close()
+	for _, r := range store.SearchRepositories(strings.Join(query, " ")) {
+		if len(r.Tags) > 0 {
+			fmt.Printf("%s [%s]\n", r.Name, strings.Join(r.Tags, ", "))
+		} else {
+			fmt.Println(r.Name)
+		}
+	}
+}
+
+func repoTagHandler(cmd *cobra.Command, args []string) {
+	updateRepoTags(args[0], args[1:], func(r *storage.Repository, tags []string) {
+		r.AddTags(tags...)
+	})
+}
+
+func repoUntagHandler(cmd *cobra.Command, args []string) {
+	updateRepoTags(args[0], args[1:], func(r *storage.Repository, tags []string) {
+		r.RemoveTags(tags...)
+	})
+}
+
+// updateRepoTags finds the repo by name and saves it after
+// update has changed its tags
+func updateRepoTags(name string, tags []string, update func(r *storage.Repository, tags []string)) {
+	for _, t := range tags {
+		if strings.ContainsAny(t, " \t:") {
+			fmt.Printf("invalid tag %q, %s\n", t, errInvalidTag)
+			os.Exit(ExitInvalidArguments)
+		}
+	}
+
+	openStore()
+	defer store.Close()
+	repo := findRepository(name)
+	update(repo, tags)
+	if err := store.UpdateRepository(repo); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	saveStore()
+
+	if len(repo.Tags) > 0 {
+		fmt.Printf("repo %s tagged %s\n", repo.Name, strings.Join(repo.Tags, ", "))
+	} else {
+		fmt.Printf("repo %s has no tags\n", repo.Name)
+	}
+}
+
+// findRepository returns the repo with exactly the given name, or the
+// only repo matching it as a search, exiting if there isn't one
+func findRepository(name string) *storage.Repository {
+	if repo, ok := store.GetRepository(name); ok {
+		return repo
+	}
+
+	repos := store.SearchRepositories(name)
+	if len(repos) == 1 {
+		return &repos[0]
+	} else if len(repos) == 0 {
+		fmt.Printf("no repo %s found\n", name)
+		os.Exit(1)
+	}
+
+	fmt.Println("many repos match your entry, please choose one")
+	for _, r := range repos {
+		fmt.Printf("  %s\n", r.Name)
+	}
+	os.Exit(1)
+	return nil
+}
+
 func removeEmptyDirs(path, base string) error {
 	if !strings.HasSuffix(path, "/") {
 		path = path + "/"
@@ -109,3 +109,50 @@ func (suite *RepoRmCmdSuite) setupTestRepo(repo *storage.Repository) {
 func TestRepoRmSuite(t *testing.T) {
 	suite.Run(t, new(RepoRmCmdSuite))
 }
+
+type RepoTagCmdSuite struct {
+	suite.Suite
+}
+
+func (suite *RepoTagCmdSuite) SetupTest() {
+	configFS = &fs.ConfigFS{
+		FS: memfs.New(),
+	}
+	configFS.Setup()
+	store = storage.NewFileStorage(configFS.FS, configFS.CachePath())
+	store.Open()
+	suite.Nil(store.AddRepository(&storage.Repository{
+		Name: "github.com/TheHipbot/hermes",
+	}))
+	suite.Nil(store.AddRepository(&storage.Repository{
+		Name: "github.com/TheHipbot/dotfiles",
+	}))
+	suite.Nil(store.Save(), "store should be saved")
+	suite.Nil(store.Close())
+}
+
+func (suite *RepoTagCmdSuite) TestTag() {
+	repoTagCommand.Run(&cobra.Command{}, []string{"hermes", "OnCall", "frontend", "oncall"})
+	repo, ok := store.GetRepository("github.com/TheHipbot/hermes")
+	suite.True(ok)
+	suite.Equal([]string{"frontend", "oncall"}, repo.Tags, "Tags should be lowercase, sorted and without duplicates")
+
+	repos := store.SearchRepositories("tag:oncall")
+	suite.Len(repos, 1, "Tagged repo should be found by tag")
+	suite.Equal("github.com/TheHipbot/hermes", repos[0].Name)
+}
+
+func (suite *RepoTagCmdSuite) TestUntag() {
+	repoTagCommand.Run(&cobra.Command{}, []string{"github.com/TheHipbot/dotfiles", "oncall", "frontend"})
+	repoUntagCommand.Run(&cobra.Command{}, []string{"github.com/TheHipbot/dotfiles", "oncall", "q4-migration"})
+	repo, _ := store.GetRepository("github.com/TheHipbot/dotfiles")
+	suite.Equal([]string{"frontend"}, repo.Tags, "Only the given tags should be removed")
+
+	repoUntagCommand.Run(&cobra.Command{}, []string{"github.com/TheHipbot/dotfiles", "frontend"})
+	repo, _ = store.GetRepository("github.com/TheHipbot/dotfiles")
+	suite.Nil(repo.Tags, "Repo should have no tags")
+}
+
+func TestRepoTagSuite(t *testing.T) {
+	suite.Run(t, new(RepoTagCmdSuite))
+}
@@ -18,6 +18,7 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 
 	"github.com/TheHipbot/hermes/pkg/credentials"
@@ -27,6 +28,7 @@ import (
 	"github.com/TheHipbot/hermes/pkg/prompt"
 	"github.com/TheHipbot/hermes/pkg/repo"
 	"github.com/TheHipbot/hermes/pkg/storage"
+	"github.com/TheHipbot/hermes/pkg/workspace"
 	homedir "github.com/mitchellh/go-homedir"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -65,26 +67,26 @@ to quickly create a Cobra application.`,
 		}
 		return nil
 	},
-	Run: getHandler,
+	ValidArgsFunction: completeRepoNames,
+	Run:               getHandler,
 }
 
 var getCmd = &cobra.Command{
-	Use:   "get",
-	Short: "get a new repo or go to an existing",
-	Run:   getHandler,
+	Use:               "get",
+	Short:             "get a new repo or go to an existing",
+	ValidArgsFunction: completeRepoNames,
+	Run:               getHandler,
 }
 
 // TODO: add repository only on successful clone
 // TODO: update ssh to not assume username
 func getHandler(cmd *cobra.Command, args []string) {
 	if len(args) == 0 {
-		fmt.Println("Requires repo as an argument")
+		fmt.Fprintln(os.Stderr, "Requires repo as an argument")
 		os.Exit(ExitInvalidArguments)
 	}
-	repoName := args[0]
-	pathToRepo := fmt.Sprintf("%s%s/", viper.GetString("repo_path"), repoName)
-	store.Open()
-	defer store.Close()
+	repoName := strings.Join(args, " ")
+	openStore()
 
 	var selectedRepo storage.Repository
 	var remote *storage.Remote
@@ -94,86 +96,203 @@ func getHandler(cmd *cobra.Command, args []string) {
 		remote, _ = store.SearchRemote(strings.Split(selectedRepo.Name, "/")[0])
 	} else if len(cachedRepos) == 0 {
 		parts := strings.Split(repoName, "/")
-		if len(parts) < 3 {
-			fmt.Printf(`No repo found, a new repo must be in the form
+		if len(args) > 1 || len(parts) < 3 {
+			fmt.Fprintf(os.Stderr, `No repo found, a new repo must be in the form
 <remote hostname>/<user or group>/<repo name>
 `)
 			os.Exit(ExitInvalidArguments)
 		}
 		remoteName := parts[0]
-		remote, ok := store.SearchRemote(remoteName)
+		var ok bool
+		remote, ok = store.SearchRemote(remoteName)
 		selectedRepo = storage.Repository{
 			Name: repoName,
-			Path: pathToRepo,
 		}
-		if err := store.AddRepository(&storage.Repository{
-			Name: repoName,
-			Path: viper.GetString("repo_path"),
-		}); err != nil {
-			fmt.Printf("Error adding repo to cache %s\n%s\n", pathToRepo, err)
+		pathToRepo, err := repoPath(selectedRepo)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		selectedRepo.Path = pathToRepo
+		repoToAdd := selectedRepo
+		if err := store.AddRepository(&repoToAdd); err != nil {
+			fmt.Fprintf(os.Stderr, "Error adding repo to cache %s\n%s\n", pathToRepo, err)
 		}
 		if !ok {
 			// prompt user for protocol
 			p := prompt.CreateProtocolSelectPrompt(prompter, protocols)
 			i, _, err := p.Run()
 			if err != nil {
-				fmt.Printf("Error retrieving input\n")
+				exitIfNonInteractive(err)
+				fmt.Fprintf(os.Stderr, "Error retrieving input\n")
 				os.Exit(1)
 			}
 			remote, _ = store.SearchRemote(remoteName)
 			remote.Protocol = protocols[i]
 		}
-		store.Save()
+		saveStore()
 	} else {
 		p := prompt.CreateRepoSelectPrompt(prompter, cachedRepos)
 		i, _, err := p.Run()
-		selectedRepo = cachedRepos[i]
 		if err != nil {
-			fmt.Printf("Error selecting repo\n%s\n", err)
+			exitIfNonInteractive(err)
+			fmt.Fprintf(os.Stderr, "Error selecting repo\n%s\n", err)
 			os.Exit(1)
 		}
+		selectedRepo = cachedRepos[i]
 		remote, _ = store.SearchRemote(strings.Split(selectedRepo.Name, "/")[0])
 	}
 
-	targetRepo := repo.NewGitRepository(selectedRepo.Name, "")
+	targetRepo := gitRepository(selectedRepo, remote.Protocol)
+	targetRepo.CloneOptions = cloneOptions(cmd, selectedRepo)
+	targetRepo.CloneOptions.GitConfig = remoteGitConfig(selectedRepo.Name)
+	// the cache is closed before the clone, hooks and actions, which may
+	// run for as long as an editor or tmux session is open, so other
+	// hermes processes are not kept waiting on its lock
+	store.Close()
+
+	progress := newCloneProgress(cmd)
+	targetRepo.CloneOptions.Progress = progress.reporter(selectedRepo.Name)
+
+	err := targetRepo.Clone(selectedRepo.Path)
+	progress.clear()
+	if err == nil {
+		if err := runPostCloneHooks(selectedRepo, os.Stderr); err != nil {
+			fmt.Fprintf(os.Stderr, "Error running post-clone hooks, the repo was cloned\n%s\n", err)
+			os.Exit(1)
+		}
+	} else if err != repo.ErrRepoAlreadyExists {
+		exitIfNonInteractive(err)
+		fmt.Fprintf(os.Stderr, "Error cloning repo %s\n%s\n", selectedRepo.Path, err)
+		if hint := cloneHint(err, selectedRepo); hint != "" {
+			fmt.Fprintf(os.Stderr, "hint: %s\n", hint)
+		}
+		os.Exit(1)
+	}
+
+	if err := configFS.SetTarget(selectedRepo.Path); err != nil {
+		fmt.Fprintf(os.Stderr, "Error creating target file\n%s\n", err)
+		os.Exit(1)
+	}
+
+	if err := runPostSelectActions(cmd, selectedRepo); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// cloneHint returns what can be done about the error cloning the repo,
+// or an empty string when hermes has nothing to suggest
+func cloneHint(err error, r storage.Repository) string {
+	remoteName := strings.Split(r.Name, "/")[0]
+	switch {
+	case errors.Is(err, repo.ErrAuthFailed):
+		return fmt.Sprintf("run `hermes remote add %s` to refresh your token, or for ssh add your key to ssh-agent with `ssh-add`", remoteName)
+	case errors.Is(err, repo.ErrRepoNotFound):
+		return fmt.Sprintf("check the repo exists and you have access to it, run `hermes remote refresh` to update the cached repos, or `hermes remote add %s` to refresh your token", remoteName)
+	case errors.Is(err, repo.ErrHostUnreachable):
+		return fmt.Sprintf("check your network connection and that %s is the right host, a VPN may be needed to reach it", remoteName)
+	case errors.Is(err, repo.ErrHostKeyVerification):
+		return fmt.Sprintf("connect to %s once with `ssh` to add its key to known_hosts, if it is already there its key has changed and should be checked", remoteName)
+	case errors.Is(err, repo.ErrDiskFull):
+		return fmt.Sprintf("free up space on the disk of %s and try again", r.Path)
+	case errors.Is(err, repo.ErrPermissionDenied):
+		return fmt.Sprintf("check you can write to %s, or change where repos are cloned with `repo_path` or `path_template`", filepath.Dir(r.Path))
+	}
+	return ""
+}
+
+// newCloner creates the cloner used for git repositories
+var newCloner = repo.NewCloner
+
+// gitRepository creates the git repository used to clone or pull the
+// cached repo, its URL is chosen by the protocol of its remote
+func gitRepository(r storage.Repository, protocol string) *repo.GitRepository {
+	targetRepo := repo.NewGitRepository(r.Name, "")
 	targetRepo.Fs = appFs
-	cloner, _ := repo.NewCloner("git")
+	cloner, _ := newCloner("git")
 	targetRepo.Cloner = cloner
+	targetRepo.Prompter = prompter
 
-	switch remote.Protocol {
+	switch protocol {
 	case "ssh":
-		if selectedRepo.SSHURL != "" {
-			targetRepo.URL = selectedRepo.SSHURL
+		if r.SSHURL != "" {
+			targetRepo.URL = r.SSHURL
 			targetRepo.Protocol = "ssh"
 		} else {
-			targetRepo.URL = fmt.Sprintf("ssh://git@%s", selectedRepo.Name)
+			targetRepo.URL = repo.SSHURL(r.Name)
 			targetRepo.Protocol = "ssh"
 		}
 	case "http":
-		if selectedRepo.CloneURL != "" {
-			targetRepo.URL = selectedRepo.CloneURL
+		if r.CloneURL != "" {
+			targetRepo.URL = r.CloneURL
 		} else {
-			targetRepo.URL = fmt.Sprintf("http://%s", selectedRepo.Name)
+			targetRepo.URL = fmt.Sprintf("http://%s", r.Name)
 		}
 	default:
-		if selectedRepo.CloneURL != "" {
-			targetRepo.URL = selectedRepo.CloneURL
+		if r.CloneURL != "" {
+			targetRepo.URL = r.CloneURL
 		} else {
-			targetRepo.URL = fmt.Sprintf("https://%s", selectedRepo.Name)
+			targetRepo.URL = fmt.Sprintf("https://%s", r.Name)
 		}
+		targetRepo.Username, targetRepo.Token = remoteToken(r.Name)
 	}
+	return targetRepo
+}
 
-	if err := targetRepo.Clone(selectedRepo.Path); err != nil && err != repo.ErrRepoAlreadyExists {
-		fmt.Printf("Error cloning repo %s\n%s\n", selectedRepo.Path, err)
-		os.Exit(1)
+// remoteProtocol returns the protocol set for the remote of
+// the named repo, or an empty string if it has none
+func remoteProtocol(name string) string {
+	remote, _ := store.SearchRemote(strings.Split(name, "/")[0])
+	return remote.Protocol
+}
+
+// remoteToken returns the username and token stored for the remote of the
+// named repo, which authenticate https clones. GitHub and GitLab accept a
+// token with any username, but GitLab needs oauth2 for OAuth tokens
+func remoteToken(name string) (string, string) {
+	if credentialsStorer == nil {
+		return "", ""
+	}
+	cred, err := credentialsStorer.Get(strings.Split(name, "/")[0])
+	if err != nil || cred.Token == "" {
+		return "", ""
+	}
+	if cred.Username == "" {
+		return "oauth2", cred.Token
 	}
+	return cred.Username, cred.Token
+}
 
-	if err := configFS.SetTarget(selectedRepo.Path); err != nil {
-		fmt.Printf("Error creating target file\n%s\n", err)
+// exit ends hermes with the code, tests replace it to run
+// handlers which exit when they fail
+var exit = os.Exit
+
+// openStore opens the cache, exiting if it cannot be opened
+// or is corrupt
+func openStore() {
+	if err := store.Open(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error opening cache\n%s\n", err)
 		os.Exit(1)
 	}
 }
 
+// saveStore saves the cache, reporting when it could not be saved
+func saveStore() {
+	if err := store.Save(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error saving cache\n%s\n", err)
+	}
+}
+
+// exitIfNonInteractive prints the error and exits with ExitInputRequired
+// if the error came from a prompt which could not be shown
+func exitIfNonInteractive(err error) {
+	if prompt.IsNonInteractive(err) {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(ExitInputRequired)
+	}
+}
+
 // Execute runs the root command
 func Execute() {
 	if err := rootCmd.Execute(); err != nil {
@@ -186,6 +305,10 @@ func init() {
 	cobra.OnInitialize(initConfig)
 
 	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.hermes.yaml)")
+	rootCmd.PersistentFlags().Bool("no-interactive", false, "fail instead of prompting when input is required")
+	viper.BindPFlag("no_interactive", rootCmd.PersistentFlags().Lookup("no-interactive"))
+	viper.BindEnv("no_interactive", "HERMES_NONINTERACTIVE")
+	viper.BindEnv("target_file_path", "HERMES_TARGET_FILE")
 
 	home, err := homedir.Dir()
 	if err != nil {
@@ -194,20 +317,36 @@ func init() {
 	}
 
 	viper.SetDefault("repo_path", fmt.Sprintf("%s/hermes-repos/", home))
+	viper.SetDefault("path_template", "{{.Name}}")
 	viper.SetDefault("config_path", fmt.Sprintf("%s/.hermes/", home))
 	viper.SetDefault("target_file", ".hermes_target")
 	viper.SetDefault("cache_file", "cache.json")
+	viper.SetDefault("cache_backend", "json")
+	viper.SetDefault("cache_db_file", "cache.db")
+	viper.SetDefault("workspaces_file", "workspaces.yml")
 	viper.SetDefault("alias_name", "hermes")
 	viper.SetDefault("remotes_file", "remotes.json")
 	viper.SetDefault("credentials_type", "none")
 	viper.SetDefault("credentials_file", "credentials.yml")
 
+	addActionFlags(rootCmd)
+	addActionFlags(getCmd)
+	addCloneFlags(rootCmd)
+	addCloneFlags(getCmd)
+
 	rootCmd.AddCommand(aliasCmd)
+	rootCmd.AddCommand(cacheCmd)
+	rootCmd.AddCommand(cloneCmd)
+	rootCmd.AddCommand(completionCmd)
+	rootCmd.AddCommand(execCmd)
 	rootCmd.AddCommand(getCmd)
 	rootCmd.AddCommand(remoteCmd)
 	rootCmd.AddCommand(repoCmd)
 	rootCmd.AddCommand(setupCmd)
+	rootCmd.AddCommand(statusCmd)
+	rootCmd.AddCommand(syncCmd)
 	rootCmd.AddCommand(versionCmd)
+	rootCmd.AddCommand(workspaceCmd)
 }
 
 // initConfig reads in config file and ENV variables if set.
@@ -234,13 +373,18 @@ func initConfig() {
 	configFS = fs.NewConfigFS()
 	appFs = osfs.New("")
 
-	prompter = &prompt.Prompter{}
+	if viper.GetBool("no_interactive") {
+		prompter = &prompt.NonInteractivePrompter{}
+	} else {
+		prompter = &prompt.Prompter{}
+	}
 
-	cacheFile, err := configFS.GetCacheFile()
-	if err != nil {
-		fmt.Println("Cache file could not be opened or created")
+	var err error
+	if store, err = newStore(viper.GetString("cache_backend")); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
 	}
-	store = storage.NewStorage(cacheFile)
+	workspaces = workspace.NewStore(configFS.FS, configFS.WorkspacesPath())
 
 	switch viper.GetString("credentials_type") {
 	case "file":
@@ -13,6 +13,7 @@ import (
 	"github.com/golang/mock/gomock"
 
 	mock "github.com/TheHipbot/hermes/mock"
+	"github.com/TheHipbot/hermes/pkg/credentials"
 	"github.com/TheHipbot/hermes/pkg/fs"
 	"github.com/TheHipbot/hermes/pkg/repo"
 	"github.com/TheHipbot/hermes/pkg/storage"
@@ -142,6 +143,67 @@ func (s *RootCmdSuite) TestGetHandlerMultipleCachedRepos() {
 	s.Equal(string(content), "/repos/github.com/TheHipbot/dotfiles", "Get should find one repo and set target path")
 }
 
+func (s *RootCmdSuite) TestGetHandlerNewRepo() {
+	ctrl := gomock.NewController(s.T())
+	defer ctrl.Finish()
+	store = storage.NewFileStorage(configFS.FS, configFS.CachePath())
+	s.Nil(store.Open())
+	s.Nil(store.AddRemote("https://gitlab.com", "gitlab.com", "gitlab", "https"))
+	s.Nil(store.Save())
+	s.Nil(store.Close())
+
+	mockCloner := mock.NewMockCloner(ctrl)
+	mockCloner.
+		EXPECT().
+		Clone(gomock.Eq("/repos/gitlab.com/TheHipbot/weather"), gomock.Any()).
+		Return(nil).
+		Times(1)
+	repo.RegisterCloner("git", func() (repo.Cloner, error) {
+		return mockCloner, nil
+	})
+
+	getHandler(cmd, []string{"gitlab.com/TheHipbot/weather"})
+	s.Nil(store.Open())
+	r, ok := store.GetRepository("gitlab.com/TheHipbot/weather")
+	s.True(ok, "New repo should be added to the cache")
+	s.Equal("/repos/gitlab.com/TheHipbot/weather", r.Path, "New repo should be cached with the path it is cloned to")
+}
+
+func (s *RootCmdSuite) TestGitRepositoryToken() {
+	credentialsStorer = credentials.NewMemStorer()
+	defer func() {
+		credentialsStorer = nil
+	}()
+	s.Nil(credentialsStorer.Put("gitlab.com", credentials.Credential{Type: "token", Token: "glpat-s3cr3t"}))
+	s.Nil(credentialsStorer.Put("github.com", credentials.Credential{Type: "token", Username: "TheHipbot", Token: "ghp_s3cr3t"}))
+
+	r := gitRepository(storage.Repository{Name: "gitlab.com/TheHipbot/weather"}, "https")
+	s.Equal("https://gitlab.com/TheHipbot/weather", r.URL, "The token should not be in the URL")
+	s.Equal("oauth2", r.Username)
+	s.Equal("glpat-s3cr3t", r.Token)
+
+	r = gitRepository(storage.Repository{Name: "github.com/TheHipbot/hermes"}, "")
+	s.Equal("TheHipbot", r.Username, "The stored username should be used")
+	s.Equal("ghp_s3cr3t", r.Token)
+
+	r = gitRepository(storage.Repository{Name: "github.com/TheHipbot/hermes"}, "ssh")
+	s.Empty(r.Token, "The token should not be used over ssh")
+
+	r = gitRepository(storage.Repository{Name: "bitbucket.org/TheHipbot/notes"}, "https")
+	s.Empty(r.Token, "A remote without a token should have none")
+}
+
+func (s *RootCmdSuite) TestCloneHint() {
+	r := storage.Repository{Name: "gitlab.com/TheHipbot/weather", Path: "/repos/gitlab.com/TheHipbot/weather"}
+	s.Contains(cloneHint(fmt.Errorf("%w: Authentication failed", repo.ErrAuthFailed), r), "hermes remote add gitlab.com")
+	s.Contains(cloneHint(fmt.Errorf("%w: Repository not found.", repo.ErrRepoNotFound), r), "hermes remote refresh")
+	s.Contains(cloneHint(repo.ErrHostUnreachable, r), "network")
+	s.Contains(cloneHint(repo.ErrHostKeyVerification, r), "known_hosts")
+	s.Contains(cloneHint(repo.ErrDiskFull, r), "/repos/gitlab.com/TheHipbot/weather")
+	s.Contains(cloneHint(repo.ErrPermissionDenied, r), "/repos/gitlab.com/TheHipbot")
+	s.Empty(cloneHint(repo.ErrCloneRepo, r), "Unknown errors should have no hint")
+}
+
 func TestRootCmdSuite(t *testing.T) {
 	suite.Run(t, new(RootCmdSuite))
 }
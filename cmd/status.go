@@ -0,0 +1,144 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/TheHipbot/hermes/pkg/repo"
+	"github.com/TheHipbot/hermes/pkg/storage"
+	"github.com/spf13/cobra"
+)
+
+// getLocalStatus reads the status of a clone
+var getLocalStatus = repo.GetLocalStatus
+
+func init() {
+	statusCmd.Flags().Bool("dirty", false, "only show repos with uncommitted changes or untracked files")
+	statusCmd.Flags().Bool("unpushed", false, "only show repos with commits which are not on their upstream")
+	statusCmd.Flags().IntP("jobs", "j", 8, "number of repos to read at once")
+}
+
+var statusCmd = &cobra.Command{
+	Use:   "status [search]",
+	Short: "Show the branch and local changes of every cloned repo",
+	Long: `Status prints a table of every cached repo which is cloned in the
+repo_path, or those matching the search, with its current branch, whether
+it has uncommitted changes, how many untracked files and stashes it has,
+how far it is ahead of and behind its upstream and the age of its last
+commit. The remote is not fetched, so ahead and behind are as of the last
+fetch.`,
+	ValidArgsFunction: completeRepoNames,
+	Run:               statusHandler,
+}
+
+// repoStatus is the status of a cloned repo
+type repoStatus struct {
+	Name   string
+	Status *repo.LocalStatus
+}
+
+func statusHandler(cmd *cobra.Command, args []string) {
+	dirty, _ := cmd.Flags().GetBool("dirty")
+	unpushed, _ := cmd.Flags().GetBool("unpushed")
+	jobs, _ := cmd.Flags().GetInt("jobs")
+
+	openStore()
+	cloned := []storage.Repository{}
+	for _, r := range store.SearchRepositories(strings.Join(args, " ")) {
+		if _, err := appFs.Stat(r.Path); err == nil {
+			cloned = append(cloned, r)
+		}
+	}
+	store.Close()
+
+	var mu sync.Mutex
+	statuses := map[string]*repo.LocalStatus{}
+	results := forEachRepo(cloned, jobs, func(r storage.Repository) error {
+		s, err := getLocalStatus(appFs, r.Path)
+		if err != nil {
+			return err
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		statuses[r.Name] = s
+		return nil
+	})
+
+	rows := []repoStatus{}
+	for _, res := range results {
+		s, ok := statuses[res.Repo.Name]
+		if !ok {
+			continue
+		}
+		if !showStatus(s, dirty, unpushed) {
+			continue
+		}
+		rows = append(rows, repoStatus{
+			Name:   res.Repo.Name,
+			Status: s,
+		})
+	}
+
+	printStatusTable(os.Stdout, rows, time.Now())
+	if printFailures(os.Stdout, results) > 0 {
+		os.Exit(1)
+	}
+}
+
+// showStatus reports whether the status matches the filters, when both
+// filters are set repos matching either are shown
+func showStatus(s *repo.LocalStatus, dirty, unpushed bool) bool {
+	if !dirty && !unpushed {
+		return true
+	}
+	return (dirty && (s.Dirty || s.Untracked > 0)) || (unpushed && s.Unpushed())
+}
+
+// printStatusTable writes the statuses as a table aligned with tabs
+func printStatusTable(out io.Writer, rows []repoStatus, now time.Time) {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "REPO\tBRANCH\tCHANGES\tUNTRACKED\tAHEAD\tBEHIND\tUNPUSHED BRANCHES\tSTASHES\tLAST COMMIT")
+	for _, row := range rows {
+		s := row.Status
+		branch := s.Branch
+		if branch == "" {
+			branch = "(detached)"
+		}
+		changes := "clean"
+		if s.Dirty {
+			changes = "dirty"
+		}
+		ahead, behind := "-", "-"
+		if !s.NoUpstream {
+			ahead, behind = strconv.Itoa(s.Ahead), strconv.Itoa(s.Behind)
+		}
+		age := "-"
+		if !s.LastCommit.IsZero() {
+			age = formatAge(now.Sub(s.LastCommit))
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%s\t%s\t%d\t%d\t%s\n", row.Name, branch, changes, s.Untracked, ahead, behind, s.UnpushedBranches, s.Stashes, age)
+	}
+	w.Flush()
+}
+
+// formatAge formats a duration in its largest whole unit
+func formatAge(d time.Duration) string {
+	day := 24 * time.Hour
+	switch {
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d/time.Minute))
+	case d < 2*day:
+		return fmt.Sprintf("%dh", int(d/time.Hour))
+	case d < 60*day:
+		return fmt.Sprintf("%dd", int(d/day))
+	case d < 730*day:
+		return fmt.Sprintf("%dmo", int(d/(30*day)))
+	}
+	return fmt.Sprintf("%dy", int(d/(365*day)))
+}
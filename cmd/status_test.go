@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/TheHipbot/hermes/pkg/repo"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/suite"
+	billy "gopkg.in/src-d/go-billy.v4"
+)
+
+type StatusCmdSuite struct {
+	suite.Suite
+	read []string
+}
+
+func (suite *StatusCmdSuite) SetupTest() {
+	suite.read = []string{}
+	getLocalStatus = func(fs billy.Filesystem, path string) (*repo.LocalStatus, error) {
+		suite.read = append(suite.read, path)
+		return &repo.LocalStatus{Branch: "main"}, nil
+	}
+	setupCache(suite.T(), nil, named(
+		"github.com/TheHipbot/hermes",
+		"github.com/TheHipbot/dotfiles",
+	)...)
+}
+
+func (suite *StatusCmdSuite) TearDownTest() {
+	getLocalStatus = repo.GetLocalStatus
+}
+
+func (suite *StatusCmdSuite) TestOnlyClonedRepos() {
+	suite.Nil(appFs.MkdirAll("/repos/github.com/TheHipbot/hermes", 0755))
+	cmd := &cobra.Command{}
+	cmd.Flags().Bool("dirty", false, "")
+	cmd.Flags().Bool("unpushed", false, "")
+	cmd.Flags().IntP("jobs", "j", 1, "")
+	statusCmd.Run(cmd, []string{})
+	suite.Equal([]string{"/repos/github.com/TheHipbot/hermes"}, suite.read, "Only cloned repos should be read")
+}
+
+func (suite *StatusCmdSuite) TestShowStatus() {
+	clean := &repo.LocalStatus{Branch: "main"}
+	untracked := &repo.LocalStatus{Branch: "main", Untracked: 1}
+	ahead := &repo.LocalStatus{Branch: "main", Ahead: 2}
+	otherBranch := &repo.LocalStatus{Branch: "main", UnpushedBranches: 1}
+	suite.True(showStatus(clean, false, false), "Every repo is shown without filters")
+	suite.False(showStatus(clean, true, true))
+	suite.True(showStatus(untracked, true, false), "Untracked files should count as dirty")
+	suite.False(showStatus(untracked, false, true))
+	suite.True(showStatus(ahead, true, true), "Repos matching either filter should be shown")
+	suite.True(showStatus(otherBranch, false, true), "Unpushed branches other than HEAD's should be shown")
+}
+
+func (suite *StatusCmdSuite) TestPrintStatusTable() {
+	now := time.Date(2020, 3, 1, 0, 0, 0, 0, time.UTC)
+	out := &bytes.Buffer{}
+	printStatusTable(out, []repoStatus{
+		{
+			Name: "github.com/TheHipbot/hermes",
+			Status: &repo.LocalStatus{
+				Branch:           "main",
+				Dirty:            true,
+				Untracked:        3,
+				Ahead:            1,
+				Stashes:          2,
+				LastCommit:       now.Add(-3 * 24 * time.Hour),
+				UnpushedBranches: 2,
+			},
+		},
+		{
+			Name: "github.com/TheHipbot/dotfiles",
+			Status: &repo.LocalStatus{
+				NoUpstream:       true,
+				LastCommit:       now.Add(-90 * time.Minute),
+				UnpushedBranches: 1,
+			},
+		},
+	}, now)
+	suite.Equal(`REPO                           BRANCH      CHANGES  UNTRACKED  AHEAD  BEHIND  UNPUSHED BRANCHES  STASHES  LAST COMMIT
+github.com/TheHipbot/hermes    main        dirty    3          1      0       2                  2        3d
+github.com/TheHipbot/dotfiles  (detached)  clean    0          -      -       1                  0        1h
+`, out.String())
+}
+
+func (suite *StatusCmdSuite) TestFormatAge() {
+	suite.Equal("5m", formatAge(5*time.Minute))
+	suite.Equal("30h", formatAge(30*time.Hour))
+	suite.Equal("3mo", formatAge(100*24*time.Hour))
+	suite.Equal("2y", formatAge(800*24*time.Hour))
+}
+
+func TestStatusCmdSuite(t *testing.T) {
+	suite.Run(t, new(StatusCmdSuite))
+}
@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/TheHipbot/hermes/pkg/repo"
+	"github.com/TheHipbot/hermes/pkg/storage"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	syncCmd.Flags().IntP("jobs", "j", 4, "number of repos to sync at once")
+}
+
+var syncCmd = &cobra.Command{
+	Use:   "sync [search]",
+	Short: "Fetch every cloned repo and fast-forward those which are behind",
+	Long: `Sync fetches every cached repo which is cloned in the repo_path, or
+those matching the search, then fast-forwards the current branch of
+each repo which is behind its upstream. Repos with uncommitted changes,
+or which are ahead of or diverged from their upstream, are fetched but
+never changed.`,
+	ValidArgsFunction: completeRepoNames,
+	Run:               syncHandler,
+}
+
+func syncHandler(cmd *cobra.Command, args []string) {
+	jobs, _ := cmd.Flags().GetInt("jobs")
+	out := cmd.OutOrStdout()
+
+	openStore()
+	cloned := []storage.Repository{}
+	gitRepos := map[string]*repo.GitRepository{}
+	for _, r := range store.SearchRepositories(strings.Join(args, " ")) {
+		if _, err := appFs.Stat(r.Path); err != nil {
+			continue
+		}
+		cloned = append(cloned, r)
+		gitRepos[r.Name] = gitRepository(r, remoteProtocol(r.Name))
+	}
+	store.Close()
+
+	var mu sync.Mutex
+	synced := map[string]*repo.SyncResult{}
+	results := runOnRepos(out, cloned, jobs, "synced", func(r storage.Repository) error {
+		res, err := gitRepos[r.Name].Sync(r.Path)
+		if err != nil {
+			return err
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		synced[r.Name] = res
+		return nil
+	})
+
+	updated, upToDate, notPulled := 0, 0, 0
+	for _, res := range results {
+		s, ok := synced[res.Repo.Name]
+		if !ok {
+			continue
+		}
+		switch {
+		case s.Updated:
+			updated++
+		case s.Branch != "" && !s.NoUpstream && s.Ahead == 0 && s.Behind == 0:
+			upToDate++
+			continue
+		default:
+			notPulled++
+		}
+		fmt.Fprintf(out, "%s: %s\n", res.Repo.Name, s)
+	}
+
+	failed := printFailures(out, results)
+	fmt.Fprintf(out, "%d updated, %d up to date, %d not pulled, %d failed\n", updated, upToDate, notPulled, failed)
+	if failed > 0 {
+		exit(1)
+	}
+}
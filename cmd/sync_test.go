@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/TheHipbot/hermes/pkg/repo"
+	"github.com/golang/mock/gomock"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/suite"
+)
+
+type SyncCmdSuite struct {
+	suite.Suite
+	*cmdFixture
+}
+
+func (suite *SyncCmdSuite) SetupTest() {
+	suite.cmdFixture = newCmdFixture(suite.T(), nil, named(
+		"github.com/TheHipbot/hermes",
+		"github.com/TheHipbot/dotfiles",
+		"github.com/TheHipbot/weather",
+	)...)
+}
+
+func (suite *SyncCmdSuite) TearDownTest() {
+	suite.tearDown()
+}
+
+func (suite *SyncCmdSuite) runSync() string {
+	out := &bytes.Buffer{}
+	cmd := &cobra.Command{}
+	cmd.Flags().IntP("jobs", "j", 1, "")
+	cmd.SetOut(out)
+	syncHandler(cmd, []string{})
+	return out.String()
+}
+
+func (suite *SyncCmdSuite) TestSyncClonedRepos() {
+	suite.Nil(appFs.MkdirAll("/repos/github.com/TheHipbot/hermes", 0755))
+	suite.Nil(appFs.MkdirAll("/repos/github.com/TheHipbot/dotfiles", 0755))
+	suite.cloner.
+		EXPECT().
+		Sync("/repos/github.com/TheHipbot/hermes", gomock.Any()).
+		Do(func(string, *repo.SyncOptions) {
+			suite.False(suite.cache.open, "The cache should be closed while repos sync")
+		}).
+		Return(&repo.SyncResult{Branch: "main", Behind: 2, Updated: true}, nil)
+	suite.cloner.
+		EXPECT().
+		Sync("/repos/github.com/TheHipbot/dotfiles", gomock.Any()).
+		Return(&repo.SyncResult{Branch: "main", Ahead: 1, Behind: 1}, nil)
+
+	out := suite.runSync()
+	suite.True(strings.HasSuffix(out, "1 updated, 0 up to date, 1 not pulled, 0 failed\n"), out)
+	suite.NotContains(out, "weather", "Repos which are not cloned should not be synced")
+	suite.Equal(-1, suite.exitCode, "Sync should not fail")
+}
+
+func (suite *SyncCmdSuite) TestSyncContinuesPastFailures() {
+	suite.Nil(appFs.MkdirAll("/repos/github.com/TheHipbot/hermes", 0755))
+	suite.Nil(appFs.MkdirAll("/repos/github.com/TheHipbot/weather", 0755))
+	suite.cloner.
+		EXPECT().
+		Sync("/repos/github.com/TheHipbot/hermes", gomock.Any()).
+		Return(nil, repo.ErrFetchRepo)
+	suite.cloner.
+		EXPECT().
+		Sync("/repos/github.com/TheHipbot/weather", gomock.Any()).
+		Return(&repo.SyncResult{Branch: "main"}, nil)
+
+	out := suite.runSync()
+	suite.Contains(out, "github.com/TheHipbot/hermes", "The failure should be printed")
+	suite.True(strings.HasSuffix(out, "0 updated, 1 up to date, 0 not pulled, 1 failed\n"), out)
+	suite.Equal(1, suite.exitCode, "Sync should fail when any repo fails")
+}
+
+func TestSyncCmdSuite(t *testing.T) {
+	suite.Run(t, new(SyncCmdSuite))
+}
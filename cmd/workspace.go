@@ -0,0 +1,373 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/TheHipbot/hermes/pkg/repo"
+	"github.com/TheHipbot/hermes/pkg/storage"
+	"github.com/TheHipbot/hermes/pkg/workspace"
+	"github.com/spf13/cobra"
+)
+
+var (
+	workspaces *workspace.Store
+
+	errAmbiguousRepo = errors.New("many repos match, use the full repo name or add it with --query")
+)
+
+func init() {
+	workspaceCmd.AddCommand(workspaceCreateCmd)
+	workspaceCmd.AddCommand(workspaceAddCmd)
+	workspaceCmd.AddCommand(workspaceRmCmd)
+	workspaceCmd.AddCommand(workspaceListCmd)
+	workspaceCmd.AddCommand(workspaceCloneCmd)
+	workspaceCmd.AddCommand(workspacePullCmd)
+	workspaceCmd.AddCommand(workspaceExportCmd)
+	workspaceCmd.AddCommand(workspaceImportCmd)
+
+	workspaceAddCmd.Flags().BoolP("query", "q", false, "add the arguments as search queries instead of repos")
+	workspaceCloneCmd.Flags().IntP("jobs", "j", 4, "number of repos to clone at once")
+	addCloneFlags(workspaceCloneCmd)
+	workspacePullCmd.Flags().IntP("jobs", "j", 4, "number of repos to pull at once")
+	workspaceExportCmd.Flags().StringP("output", "o", "", "file to write the workspace to (default is stdout)")
+	workspaceImportCmd.Flags().Bool("replace", false, "replace an existing workspace with the same name")
+}
+
+// workspaceCmd represents the base workspace command when called without any subcommands
+var workspaceCmd = &cobra.Command{
+	Use:     "workspace [subcommand]",
+	Aliases: []string{"ws"},
+	Short:   "Manage named sets of repos which are cloned and updated together",
+	Run: func(cmd *cobra.Command, args []string) {
+		cmd.Help()
+		os.Exit(0)
+	},
+}
+
+var workspaceCreateCmd = &cobra.Command{
+	Use:   "create [workspace] [repo...]",
+	Short: "Create a workspace, optionally with repos",
+	Args:  cobra.MinimumNArgs(1),
+	Run:   workspaceCreateHandler,
+}
+
+var workspaceAddCmd = &cobra.Command{
+	Use:   "add [workspace] [repo or query...]",
+	Short: "Add repos or search queries to a workspace",
+	Args:  cobra.MinimumNArgs(2),
+	Run:   workspaceAddHandler,
+}
+
+var workspaceRmCmd = &cobra.Command{
+	Use:     "rm [workspace] [repo or query...]",
+	Aliases: []string{"remove"},
+	Short:   "Remove repos or queries from a workspace, or the workspace itself",
+	Args:    cobra.MinimumNArgs(1),
+	Run:     workspaceRmHandler,
+}
+
+var workspaceListCmd = &cobra.Command{
+	Use:     "list [workspace]",
+	Aliases: []string{"ls"},
+	Short:   "List workspaces, or the repos in a workspace",
+	Args:    cobra.MaximumNArgs(1),
+	Run:     workspaceListHandler,
+}
+
+var workspaceCloneCmd = &cobra.Command{
+	Use:   "clone [workspace]",
+	Short: "Clone every repo in a workspace which is not already cloned",
+	Args:  cobra.ExactArgs(1),
+	Run:   workspaceCloneHandler,
+}
+
+var workspacePullCmd = &cobra.Command{
+	Use:   "pull [workspace]",
+	Short: "Fast-forward every cloned repo in a workspace",
+	Args:  cobra.ExactArgs(1),
+	Run:   workspacePullHandler,
+}
+
+var workspaceExportCmd = &cobra.Command{
+	Use:   "export [workspace]",
+	Short: "Write a workspace as yaml to share with others",
+	Args:  cobra.ExactArgs(1),
+	Run:   workspaceExportHandler,
+}
+
+var workspaceImportCmd = &cobra.Command{
+	Use:   "import [file]",
+	Short: "Add a workspace from a yaml file written by export",
+	Args:  cobra.ExactArgs(1),
+	Run:   workspaceImportHandler,
+}
+
+// loadWorkspaces loads the workspaces, exiting if they cannot be read
+func loadWorkspaces() {
+	if err := workspaces.Load(); err != nil {
+		fmt.Printf("Error reading workspaces\n%s\n", err)
+		os.Exit(1)
+	}
+}
+
+// saveWorkspaces saves the workspaces, exiting if they cannot be written
+func saveWorkspaces() {
+	if err := workspaces.Save(); err != nil {
+		fmt.Printf("Error saving workspaces\n%s\n", err)
+		os.Exit(1)
+	}
+}
+
+// getWorkspace returns the named workspace, exiting if it does not exist
+func getWorkspace(name string) *workspace.Workspace {
+	w, err := workspaces.Get(name)
+	if err != nil {
+		fmt.Printf("%s: %s\n", err, name)
+		os.Exit(1)
+	}
+	return w
+}
+
+func workspaceCreateHandler(cmd *cobra.Command, args []string) {
+	loadWorkspaces()
+	w, err := workspaces.Create(args[0])
+	if err != nil {
+		fmt.Printf("%s: %s\n", err, args[0])
+		os.Exit(1)
+	}
+	if len(args) > 1 {
+		addWorkspaceRepos(w, args[1:])
+	}
+	saveWorkspaces()
+	fmt.Printf("workspace %s created\n", w.Name)
+}
+
+func workspaceAddHandler(cmd *cobra.Command, args []string) {
+	loadWorkspaces()
+	w := getWorkspace(args[0])
+	if query, _ := cmd.Flags().GetBool("query"); query {
+		w.AddQueries(args[1:]...)
+	} else {
+		addWorkspaceRepos(w, args[1:])
+	}
+	saveWorkspaces()
+}
+
+// addWorkspaceRepos adds repos to the workspace by their full name, each
+// argument is either the full name of a repo or a search matching one
+// repo in the cache
+func addWorkspaceRepos(w *workspace.Workspace, args []string) {
+	openStore()
+	defer store.Close()
+
+	names := []string{}
+	for _, arg := range args {
+		name, err := resolveRepoName(arg)
+		if err != nil {
+			fmt.Printf("%s: %s\n", err, arg)
+			os.Exit(ExitInvalidArguments)
+		}
+		names = append(names, name)
+	}
+	w.AddRepos(names...)
+}
+
+// resolveRepoName returns the full name of the repo given by arg, which
+// may be a repo which is not yet in the cache
+func resolveRepoName(arg string) (string, error) {
+	if r, ok := store.GetRepository(arg); ok {
+		return r.Name, nil
+	}
+	repos := store.SearchRepositories(arg)
+	switch {
+	case len(repos) == 1:
+		return repos[0].Name, nil
+	case len(repos) > 1:
+		return "", errAmbiguousRepo
+	case len(strings.Split(arg, "/")) >= 3:
+		return arg, nil
+	}
+	return "", storage.ErrRepoNotFound
+}
+
+func workspaceRmHandler(cmd *cobra.Command, args []string) {
+	loadWorkspaces()
+	if len(args) == 1 {
+		if err := workspaces.Delete(args[0]); err != nil {
+			fmt.Printf("%s: %s\n", err, args[0])
+			os.Exit(1)
+		}
+		saveWorkspaces()
+		fmt.Printf("workspace %s removed\n", args[0])
+		return
+	}
+
+	w := getWorkspace(args[0])
+	if w.Remove(args[1:]...) == 0 {
+		fmt.Printf("none of the given repos or queries are in workspace %s\n", w.Name)
+		os.Exit(1)
+	}
+	saveWorkspaces()
+}
+
+func workspaceListHandler(cmd *cobra.Command, args []string) {
+	loadWorkspaces()
+	if len(args) == 0 {
+		for _, w := range workspaces.List() {
+			fmt.Printf("%s (%d repos, %d queries)\n", w.Name, len(w.Repos), len(w.Queries))
+		}
+		return
+	}
+
+	w := getWorkspace(args[0])
+	openStore()
+	defer store.Close()
+	for _, r := range workspaceMembers(w) {
+		if _, err := appFs.Stat(r.Path); err != nil {
+			fmt.Printf("%s (not cloned)\n", r.Name)
+		} else {
+			fmt.Println(r.Name)
+		}
+	}
+}
+
+// workspaceMembers returns the repos in the workspace sorted by name,
+// repos which are not in the cache are given a path by the path_template
+func workspaceMembers(w *workspace.Workspace) []storage.Repository {
+	members := map[string]storage.Repository{}
+	for _, name := range w.Repos {
+		if r, ok := store.GetRepository(name); ok {
+			members[name] = *r
+			continue
+		}
+		r := storage.Repository{
+			Name: name,
+		}
+		path, err := repoPath(r)
+		if err != nil {
+			fmt.Println(err)
+			store.Close()
+			os.Exit(1)
+		}
+		r.Path = path
+		members[name] = r
+	}
+	for _, q := range w.Queries {
+		for _, r := range store.SearchRepositories(q) {
+			members[r.Name] = r
+		}
+	}
+
+	results := make([]storage.Repository, 0, len(members))
+	for _, r := range members {
+		results = append(results, r)
+	}
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Name < results[j].Name
+	})
+	return results
+}
+
+func workspaceCloneHandler(cmd *cobra.Command, args []string) {
+	jobs, _ := cmd.Flags().GetInt("jobs")
+	loadWorkspaces()
+	w := getWorkspace(args[0])
+	openStore()
+	members := workspaceMembers(w)
+	toClone := []storage.Repository{}
+	for _, r := range members {
+		if _, err := appFs.Stat(r.Path); err != nil {
+			toClone = append(toClone, r)
+		}
+	}
+
+	results := cloneRepos(cmd, toClone, jobs)
+	failed := printFailures(os.Stderr, results)
+	fmt.Fprintf(os.Stderr, "%d cloned, %d already cloned, %d failed\n", len(results)-failed, len(members)-len(toClone), failed)
+	if failed > 0 {
+		os.Exit(1)
+	}
+}
+
+func workspacePullHandler(cmd *cobra.Command, args []string) {
+	jobs, _ := cmd.Flags().GetInt("jobs")
+	loadWorkspaces()
+	w := getWorkspace(args[0])
+	openStore()
+	toPull := []storage.Repository{}
+	gitRepos := map[string]*repo.GitRepository{}
+	notCloned := 0
+	for _, r := range workspaceMembers(w) {
+		if _, err := appFs.Stat(r.Path); err != nil {
+			notCloned++
+			continue
+		}
+		toPull = append(toPull, r)
+		gitRepos[r.Name] = gitRepository(r, remoteProtocol(r.Name))
+	}
+	// nothing is saved after pulling, so the cache is not reopened
+	store.Close()
+
+	results := runOnRepos(os.Stderr, toPull, jobs, "pulled", func(r storage.Repository) error {
+		return gitRepos[r.Name].Pull(r.Path)
+	})
+
+	failed := printFailures(os.Stderr, results)
+	fmt.Fprintf(os.Stderr, "%d pulled, %d not cloned, %d failed\n", len(results)-failed, notCloned, failed)
+	if failed > 0 {
+		os.Exit(1)
+	}
+}
+
+func workspaceExportHandler(cmd *cobra.Command, args []string) {
+	loadWorkspaces()
+	w := getWorkspace(args[0])
+
+	output, _ := cmd.Flags().GetString("output")
+	var out io.Writer = os.Stdout
+	if output != "" {
+		f, err := appFs.Create(output)
+		if err != nil {
+			fmt.Printf("Error creating %s\n%s\n", output, err)
+			os.Exit(1)
+		}
+		defer f.Close()
+		out = f
+	}
+
+	if err := w.Export(out); err != nil {
+		fmt.Printf("Error exporting workspace\n%s\n", err)
+		os.Exit(1)
+	}
+}
+
+func workspaceImportHandler(cmd *cobra.Command, args []string) {
+	f, err := appFs.Open(args[0])
+	if err != nil {
+		fmt.Printf("Error opening %s\n%s\n", args[0], err)
+		os.Exit(1)
+	}
+	defer f.Close()
+
+	w, err := workspace.Import(f)
+	if err != nil {
+		fmt.Printf("Error importing workspace\n%s\n", err)
+		os.Exit(1)
+	}
+
+	loadWorkspaces()
+	if replace, _ := cmd.Flags().GetBool("replace"); !replace {
+		if _, err := workspaces.Get(w.Name); err == nil {
+			fmt.Printf("%s: %s, use --replace to overwrite it\n", workspace.ErrWorkspaceExists, w.Name)
+			os.Exit(1)
+		}
+	}
+	workspaces.Put(w)
+	saveWorkspaces()
+	fmt.Printf("workspace %s imported\n", w.Name)
+}
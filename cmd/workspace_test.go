@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/TheHipbot/hermes/pkg/repo"
+	"github.com/TheHipbot/hermes/pkg/storage"
+	"github.com/TheHipbot/hermes/pkg/workspace"
+	"github.com/golang/mock/gomock"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/suite"
+)
+
+type WorkspaceCmdSuite struct {
+	suite.Suite
+	*cmdFixture
+}
+
+func (suite *WorkspaceCmdSuite) SetupTest() {
+	viper.Set("repo_path", "/repos/")
+	suite.cmdFixture = newCmdFixture(suite.T(), nil,
+		storage.Repository{Name: "github.com/payments/api", Tags: []string{"payments"}},
+		storage.Repository{Name: "github.com/payments/web", Tags: []string{"payments"}},
+		storage.Repository{Name: "github.com/TheHipbot/hermes"},
+	)
+}
+
+func (suite *WorkspaceCmdSuite) TearDownTest() {
+	suite.tearDown()
+}
+
+func (suite *WorkspaceCmdSuite) TestCreateAndAdd() {
+	workspaceCreateCmd.Run(&cobra.Command{}, []string{"payments", "hermes"})
+	cmd := &cobra.Command{}
+	cmd.Flags().BoolP("query", "q", true, "")
+	workspaceAddCmd.Run(cmd, []string{"payments", "tag:payments"})
+
+	suite.Nil(workspaces.Load())
+	w, err := workspaces.Get("payments")
+	suite.Nil(err, "Workspace should be saved")
+	suite.Equal([]string{"github.com/TheHipbot/hermes"}, w.Repos, "Repo should be added by its full name")
+	suite.Equal([]string{"tag:payments"}, w.Queries)
+
+	suite.Nil(store.Open())
+	defer store.Close()
+	members := workspaceMembers(w)
+	suite.Len(members, 3, "Members should include repos matching queries")
+	suite.Equal("github.com/TheHipbot/hermes", members[0].Name, "Members should be sorted by name")
+}
+
+func (suite *WorkspaceCmdSuite) TestResolveRepoName() {
+	suite.Nil(store.Open())
+	defer store.Close()
+
+	name, err := resolveRepoName("payments/web")
+	suite.Nil(err)
+	suite.Equal("github.com/payments/web", name)
+
+	_, err = resolveRepoName("payments")
+	suite.Equal(errAmbiguousRepo, err, "A search matching many repos should error")
+
+	name, err = resolveRepoName("gitlab.com/payments/ledger")
+	suite.Nil(err, "A full name not in the cache should be used as is")
+	suite.Equal("gitlab.com/payments/ledger", name)
+
+	_, err = resolveRepoName("ledger")
+	suite.Equal(storage.ErrRepoNotFound, err)
+}
+
+func (suite *WorkspaceCmdSuite) TestClone() {
+	workspaces.Put(&workspace.Workspace{
+		Name:    "payments",
+		Repos:   []string{"gitlab.com/payments/ledger"},
+		Queries: []string{"tag:payments"},
+	})
+	suite.Nil(workspaces.Save())
+	suite.Nil(appFs.MkdirAll("/repos/github.com/payments/web", 0755))
+
+	suite.cloner.
+		EXPECT().
+		Clone("/repos/github.com/payments/api", gomock.Any()).
+		Do(func(string, *repo.CloneOptions) {
+			suite.False(suite.cache.open, "The cache should be closed while repos clone")
+		}).
+		Return(nil)
+	suite.cloner.
+		EXPECT().
+		Clone("/repos/gitlab.com/payments/ledger", withProgress(repo.CloneOptions{URL: "https://gitlab.com/payments/ledger", LFS: true})).
+		Return(nil)
+
+	cmd := &cobra.Command{}
+	cmd.Flags().IntP("jobs", "j", 2, "")
+	workspaceCloneCmd.Run(cmd, []string{"payments"})
+
+	suite.Nil(store.Open())
+	defer store.Close()
+	_, ok := store.GetRepository("gitlab.com/payments/ledger")
+	suite.True(ok, "Cloned repo should be added to the cache")
+}
+
+func (suite *WorkspaceCmdSuite) TestPull() {
+	workspaces.Put(&workspace.Workspace{
+		Name:    "payments",
+		Queries: []string{"tag:payments"},
+	})
+	suite.Nil(workspaces.Save())
+	suite.Nil(appFs.MkdirAll("/repos/github.com/payments/web", 0755))
+
+	suite.cloner.
+		EXPECT().
+		Pull("/repos/github.com/payments/web", gomock.Any()).
+		Do(func(string, *repo.PullOptions) {
+			suite.False(suite.cache.open, "The cache should be closed while repos pull")
+		}).
+		Return(nil)
+
+	cmd := &cobra.Command{}
+	cmd.Flags().IntP("jobs", "j", 2, "")
+	workspacePullCmd.Run(cmd, []string{"payments"})
+}
+
+func TestWorkspaceCmdSuite(t *testing.T) {
+	suite.Run(t, new(WorkspaceCmdSuite))
+}
@@ -46,3 +46,46 @@ func (mr *MockClonerMockRecorder) Clone(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Clone", reflect.TypeOf((*MockCloner)(nil).Clone), arg0, arg1)
 }
+
+// Configure mocks base method
+func (m *MockCloner) Configure(arg0 string, arg1 map[string]string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Configure", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Configure indicates an expected call of Configure
+func (mr *MockClonerMockRecorder) Configure(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Configure", reflect.TypeOf((*MockCloner)(nil).Configure), arg0, arg1)
+}
+
+// Pull mocks base method
+func (m *MockCloner) Pull(arg0 string, arg1 *repo.PullOptions) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Pull", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Pull indicates an expected call of Pull
+func (mr *MockClonerMockRecorder) Pull(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Pull", reflect.TypeOf((*MockCloner)(nil).Pull), arg0, arg1)
+}
+
+// Sync mocks base method
+func (m *MockCloner) Sync(arg0 string, arg1 *repo.SyncOptions) (*repo.SyncResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Sync", arg0, arg1)
+	ret0, _ := ret[0].(*repo.SyncResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Sync indicates an expected call of Sync
+func (mr *MockClonerMockRecorder) Sync(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Sync", reflect.TypeOf((*MockCloner)(nil).Sync), arg0, arg1)
+}
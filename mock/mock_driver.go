@@ -60,10 +60,10 @@ func (mr *MockDriverMockRecorder) Authenticate(arg0 interface{}) *gomock.Call {
 }
 
 // GetRepos mocks base method
-func (m *MockDriver) GetRepos() ([]map[string]string, error) {
+func (m *MockDriver) GetRepos() ([]remote.Repo, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetRepos")
-	ret0, _ := ret[0].([]map[string]string)
+	ret0, _ := ret[0].([]remote.Repo)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -85,3 +85,17 @@ func (mr *MockDriverMockRecorder) SetHost(arg0 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetHost", reflect.TypeOf((*MockDriver)(nil).SetHost), arg0)
 }
+
+// StreamRepos mocks base method
+func (m *MockDriver) StreamRepos(arg0 remote.PageFunc) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StreamRepos", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// StreamRepos indicates an expected call of StreamRepos
+func (mr *MockDriverMockRecorder) StreamRepos(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StreamRepos", reflect.TypeOf((*MockDriver)(nil).StreamRepos), arg0)
+}
@@ -125,6 +125,20 @@ func (mr *MockFactoryMockRecorder) CreateInputPrompt(arg0 interface{}) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateInputPrompt", reflect.TypeOf((*MockFactory)(nil).CreateInputPrompt), arg0)
 }
 
+// CreateMaskedInputPrompt mocks base method
+func (m *MockFactory) CreateMaskedInputPrompt(arg0 string) prompt.InputPrompt {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateMaskedInputPrompt", arg0)
+	ret0, _ := ret[0].(prompt.InputPrompt)
+	return ret0
+}
+
+// CreateMaskedInputPrompt indicates an expected call of CreateMaskedInputPrompt
+func (mr *MockFactoryMockRecorder) CreateMaskedInputPrompt(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateMaskedInputPrompt", reflect.TypeOf((*MockFactory)(nil).CreateMaskedInputPrompt), arg0)
+}
+
 // CreateSelectPrompt mocks base method
 func (m *MockFactory) CreateSelectPrompt(arg0 string, arg1 interface{}, arg2 *promptui.SelectTemplates) prompt.SelectPrompt {
 	m.ctrl.T.Helper()
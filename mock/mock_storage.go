@@ -75,6 +75,21 @@ func (mr *MockStorageMockRecorder) Close() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Close", reflect.TypeOf((*MockStorage)(nil).Close))
 }
 
+// GetRepository mocks base method
+func (m *MockStorage) GetRepository(arg0 string) (*storage.Repository, bool) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRepository", arg0)
+	ret0, _ := ret[0].(*storage.Repository)
+	ret1, _ := ret[1].(bool)
+	return ret0, ret1
+}
+
+// GetRepository indicates an expected call of GetRepository
+func (mr *MockStorageMockRecorder) GetRepository(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRepository", reflect.TypeOf((*MockStorage)(nil).GetRepository), arg0)
+}
+
 // ListRemotes mocks base method
 func (m *MockStorage) ListRemotes() []*storage.Remote {
 	m.ctrl.T.Helper()
@@ -90,9 +105,11 @@ func (mr *MockStorageMockRecorder) ListRemotes() *gomock.Call {
 }
 
 // Open mocks base method
-func (m *MockStorage) Open() {
+func (m *MockStorage) Open() error {
 	m.ctrl.T.Helper()
-	m.ctrl.Call(m, "Open")
+	ret := m.ctrl.Call(m, "Open")
+	ret0, _ := ret[0].(error)
+	return ret0
 }
 
 // Open indicates an expected call of Open
@@ -157,3 +174,17 @@ func (mr *MockStorageMockRecorder) SearchRepositories(arg0 interface{}) *gomock.
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchRepositories", reflect.TypeOf((*MockStorage)(nil).SearchRepositories), arg0)
 }
+
+// UpdateRepository mocks base method
+func (m *MockStorage) UpdateRepository(arg0 *storage.Repository) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateRepository", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateRepository indicates an expected call of UpdateRepository
+func (mr *MockStorageMockRecorder) UpdateRepository(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateRepository", reflect.TypeOf((*MockStorage)(nil).UpdateRepository), arg0)
+}
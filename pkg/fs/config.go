@@ -36,14 +36,17 @@ func (c *ConfigFS) Setup() error {
 	return c.FS.MkdirAll(viper.GetString("config_path"), 0751)
 }
 
-// SetTarget creates a target file with the directory
-// to move to
+// SetTarget writes the directory to move to into the target file. When
+// the alias provides a per-invocation target file through target_file_path
+// that file is used, otherwise the target file in the config dir is created
 func (c *ConfigFS) SetTarget(target string) error {
-	targetFilePath := fmt.Sprintf("%s%s", viper.GetString("config_path"), viper.GetString("target_file"))
-
-	// check for config dir
-	if err := c.checkForConfigDir(); err != nil {
-		return err
+	targetFilePath := viper.GetString("target_file_path")
+	if targetFilePath == "" {
+		// check for config dir
+		if err := c.checkForConfigDir(); err != nil {
+			return err
+		}
+		targetFilePath = fmt.Sprintf("%s%s", viper.GetString("config_path"), viper.GetString("target_file"))
 	}
 
 	file, err := c.FS.Create(targetFilePath)
@@ -58,12 +61,27 @@ func (c *ConfigFS) SetTarget(target string) error {
 	return nil
 }
 
+// CachePath returns the path of the cache file in the config folder
+func (c *ConfigFS) CachePath() string {
+	return fmt.Sprintf("%s%s", viper.GetString("config_path"), viper.GetString("cache_file"))
+}
+
 // GetCacheFile gets the cache file from the config folder, if file doesn't exists
 // it attempts to create it
 func (c *ConfigFS) GetCacheFile() (billy.File, error) {
-	cacheFilePath := fmt.Sprintf("%s%s", viper.GetString("config_path"), viper.GetString("cache_file"))
+	cacheFilePath := c.CachePath()
 	if _, err := c.FS.Stat(cacheFilePath); err != nil {
 		return c.FS.Create(cacheFilePath)
 	}
 	return c.FS.OpenFile(cacheFilePath, os.O_RDWR, 0666)
 }
+
+// WorkspacesPath returns the path of the workspaces file in the config folder
+func (c *ConfigFS) WorkspacesPath() string {
+	return fmt.Sprintf("%s%s", viper.GetString("config_path"), viper.GetString("workspaces_file"))
+}
+
+// CacheDBPath returns the path of the cache database in the config folder
+func (c *ConfigFS) CacheDBPath() string {
+	return fmt.Sprintf("%s%s", viper.GetString("config_path"), viper.GetString("cache_db_file"))
+}
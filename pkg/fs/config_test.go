@@ -31,6 +31,7 @@ func (s *ConfigFSSuite) SetupTest() {
 	viper.Set("config_path", testConfigPath)
 	viper.Set("target_file", testTargetFile)
 	viper.Set("cache_file", testCacheFile)
+	viper.Set("target_file_path", "")
 	cfs = ConfigFS{
 		FS: memfs.New(),
 	}
@@ -61,6 +62,22 @@ func (s *ConfigFSSuite) TestSetTarget() {
 	s.True(strings.Contains(string(bs), target), "Target file content incorrect")
 }
 
+func (s *ConfigFSSuite) TestSetTargetFilePath() {
+	target := "/repo_dir/github.com/TheHipbot/hermes/"
+	targetFilePath := "/tmp/hermes_target.abc123"
+	viper.Set("target_file_path", targetFilePath)
+
+	s.Nil(cfs.SetTarget(target), "SetTarget should not require the config dir with a target file path")
+	file, err := cfs.FS.Open(targetFilePath)
+	s.Nil(err, "SetTarget should create the per-invocation target file")
+	content, err := ioutil.ReadAll(file)
+	s.Nil(err, "Target file should be read")
+	s.Equal(target, string(content), "Target file content incorrect")
+
+	_, err = cfs.FS.Stat(fmt.Sprintf("%s%s", testConfigPath, testTargetFile))
+	s.NotNil(err, "SetTarget should not write the shared target file")
+}
+
 func (s *ConfigFSSuite) GetCacheFileCreateTest() {
 	file, err := cfs.GetCacheFile()
 	s.NotNil(err, "GetCacheFile should not error")
@@ -0,0 +1,66 @@
+package prompt
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/manifoldco/promptui"
+)
+
+// NonInteractiveError is returned by prompts created by the
+// NonInteractivePrompter when they are run
+type NonInteractiveError struct {
+	Label string
+}
+
+func (e *NonInteractiveError) Error() string {
+	return fmt.Sprintf("input required (%s) but hermes is running non-interactively", strings.TrimSpace(e.Label))
+}
+
+// IsNonInteractive returns true if the error was returned from a prompt
+// which could not be shown because hermes is non-interactive
+func IsNonInteractive(err error) bool {
+	_, ok := err.(*NonInteractiveError)
+	return ok
+}
+
+// NonInteractivePrompter is an implementation of Factory which creates
+// prompts that fail immediately with a NonInteractiveError
+type NonInteractivePrompter struct{}
+
+type nonInteractiveSelect struct {
+	label string
+}
+
+type nonInteractiveInput struct {
+	label string
+}
+
+// CreateSelectPrompt creates a select prompt which always fails
+func (n *NonInteractivePrompter) CreateSelectPrompt(label string, items interface{}, tmpls *promptui.SelectTemplates) SelectPrompt {
+	return &nonInteractiveSelect{
+		label: label,
+	}
+}
+
+// CreateInputPrompt creates an input prompt which always fails
+func (n *NonInteractivePrompter) CreateInputPrompt(label string) InputPrompt {
+	return &nonInteractiveInput{
+		label: label,
+	}
+}
+
+// CreateMaskedInputPrompt creates a masked input prompt which always fails
+func (n *NonInteractivePrompter) CreateMaskedInputPrompt(label string) InputPrompt {
+	return &nonInteractiveInput{
+		label: label,
+	}
+}
+
+func (p *nonInteractiveSelect) Run() (int, string, error) {
+	return -1, "", &NonInteractiveError{Label: p.label}
+}
+
+func (p *nonInteractiveInput) Run() (string, error) {
+	return "", &NonInteractiveError{Label: p.label}
+}
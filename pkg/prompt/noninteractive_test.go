@@ -0,0 +1,45 @@
+package prompt
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type NonInteractiveSuite struct {
+	suite.Suite
+}
+
+func (s *NonInteractiveSuite) TestSelectPromptFails() {
+	p := CreateProtocolSelectPrompt(&NonInteractivePrompter{}, []string{"https", "ssh"})
+	i, _, err := p.Run()
+	s.Equal(-1, i, "No item should be selected")
+	s.True(IsNonInteractive(err), "Error should be a NonInteractiveError")
+	s.Contains(err.Error(), "Select a protocol to use with this remote")
+}
+
+func (s *NonInteractiveSuite) TestInputPromptFails() {
+	p := CreateTokenInputPrompt(&NonInteractivePrompter{})
+	token, err := p.Run()
+	s.Empty(token, "No input should be returned")
+	s.True(IsNonInteractive(err), "Error should be a NonInteractiveError")
+	s.Contains(err.Error(), "Enter auth token")
+}
+
+func (s *NonInteractiveSuite) TestMaskedInputPromptFails() {
+	p := CreatePassphraseInputPrompt(&NonInteractivePrompter{}, "~/.ssh/id_rsa")
+	_, err := p.Run()
+	s.True(IsNonInteractive(err), "Error should be a NonInteractiveError")
+	s.Contains(err.Error(), "Enter passphrase for ~/.ssh/id_rsa")
+}
+
+func (s *NonInteractiveSuite) TestIsNonInteractive() {
+	s.False(IsNonInteractive(nil))
+	s.False(IsNonInteractive(errors.New("test")))
+	s.True(IsNonInteractive(&NonInteractiveError{}))
+}
+
+func TestNonInteractiveSuite(t *testing.T) {
+	suite.Run(t, new(NonInteractiveSuite))
+}
@@ -3,6 +3,10 @@
 package prompt
 
 import (
+	"fmt"
+	"strings"
+	"text/template"
+
 	"github.com/manifoldco/promptui"
 )
 
@@ -12,6 +16,20 @@ var (
 		Active:   "\U00002714 {{ .Name | cyan }}",
 		Inactive: "  {{ .Name | white }}",
 		Selected: "{{ .Name | green }}",
+		Details: `
+{{ if .Description }}{{ .Description }}
+{{ end }}{{ if .Language }}{{ "Language:" | faint }} {{ .Language }}  {{ end }}{{ if .Visibility }}{{ "Visibility:" | faint }} {{ .Visibility }}  {{ end }}{{ "Stars:" | faint }} {{ .Stars }}{{ if .Archived }}  {{ "archived" | yellow }}{{ end }}
+{{ if .Topics }}{{ "Topics:" | faint }} {{ join .Topics ", " }}
+{{ end }}{{ if .DefaultBranch }}{{ "Default branch:" | faint }} {{ .DefaultBranch }}
+{{ end }}{{ if .LastActivity }}{{ "Last activity:" | faint }} {{ .LastActivity.Format "2006-01-02" }}
+{{ end }}`,
+		FuncMap: repoFuncMap(),
+	}
+	selectDriverTemplates = &promptui.SelectTemplates{
+		Label:    "{{ . }}?",
+		Active:   "\U00002714 {{ .Name | cyan }}",
+		Inactive: "  {{ .Name | white }}",
+		Selected: "{{ .Name | green }}",
 	}
 	selectProtocolTemplates = &promptui.SelectTemplates{
 		Label:    "{{ . }}?",
@@ -22,6 +40,7 @@ var (
 	selectRepoLabel   = "Select a repo "
 	selectDriverLabel = "Select remote server type "
 	inputKeyLabel     = "Enter auth token "
+	passphraseLabel   = "Enter passphrase for %s "
 
 	selectProtocolLabel = "Select a protocol to use with this remote "
 )
@@ -40,6 +59,7 @@ type InputPrompt interface {
 type Factory interface {
 	CreateSelectPrompt(label string, items interface{}, tmpls *promptui.SelectTemplates) SelectPrompt
 	CreateInputPrompt(label string) InputPrompt
+	CreateMaskedInputPrompt(label string) InputPrompt
 }
 
 // Prompter is an implementation of Factory which creates prompts
@@ -52,6 +72,18 @@ type Repo struct {
 	Path string `json:"repo_path"`
 }
 
+// repoFuncMap adds the functions used by the repo details
+// template to the promptui template functions
+func repoFuncMap() template.FuncMap {
+	funcs := template.FuncMap{
+		"join": strings.Join,
+	}
+	for name, f := range promptui.FuncMap {
+		funcs[name] = f
+	}
+	return funcs
+}
+
 // CreateSelectPrompt creates a select prompt
 func (b *Prompter) CreateSelectPrompt(label string, items interface{}, tmpls *promptui.SelectTemplates) SelectPrompt {
 	return &promptui.Select{
@@ -68,6 +100,14 @@ func (b *Prompter) CreateInputPrompt(label string) InputPrompt {
 	}
 }
 
+// CreateMaskedInputPrompt creates a input prompt which hides what is typed
+func (b *Prompter) CreateMaskedInputPrompt(label string) InputPrompt {
+	return &promptui.Prompt{
+		Label: label,
+		Mask:  '*',
+	}
+}
+
 // CreateRepoSelectPrompt returns a Prompt to select a Repo cache entry from
 // a given list and return the selected repo
 func CreateRepoSelectPrompt(f Factory, repos interface{}) SelectPrompt {
@@ -76,7 +116,7 @@ func CreateRepoSelectPrompt(f Factory, repos interface{}) SelectPrompt {
 
 // CreateDriverSelectPrompt returns prompt for driver
 func CreateDriverSelectPrompt(f Factory, drivers interface{}) SelectPrompt {
-	return f.CreateSelectPrompt(selectDriverLabel, drivers, selectRepoTemplates)
+	return f.CreateSelectPrompt(selectDriverLabel, drivers, selectDriverTemplates)
 }
 
 // CreateTokenInputPrompt returns prompt for auth key
@@ -84,6 +124,11 @@ func CreateTokenInputPrompt(f Factory) InputPrompt {
 	return f.CreateInputPrompt(inputKeyLabel)
 }
 
+// CreatePassphraseInputPrompt returns prompt for the passphrase of an ssh key
+func CreatePassphraseInputPrompt(f Factory, keyPath string) InputPrompt {
+	return f.CreateMaskedInputPrompt(fmt.Sprintf(passphraseLabel, keyPath))
+}
+
 // CreateProtocolSelectPrompt returns prompt for driver
 func CreateProtocolSelectPrompt(f Factory, protocols []string) SelectPrompt {
 	return f.CreateSelectPrompt(selectProtocolLabel, protocols, selectProtocolTemplates)
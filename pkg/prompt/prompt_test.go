@@ -1,7 +1,10 @@
 package prompt
 
 import (
+	"bytes"
 	"testing"
+	"text/template"
+	"time"
 
 	"github.com/TheHipbot/hermes/pkg/storage"
 	"github.com/stretchr/testify/mock"
@@ -29,6 +32,11 @@ func (p *prompterMock) CreateInputPrompt(label string) InputPrompt {
 	return args.Get(0).(InputPrompt)
 }
 
+func (p *prompterMock) CreateMaskedInputPrompt(label string) InputPrompt {
+	args := p.Called(label)
+	return args.Get(0).(InputPrompt)
+}
+
 func (s *PromptRepoSuite) TestPrompterCreateSelectRepo() {
 	repos := []storage.Repository{
 		storage.Repository{
@@ -77,6 +85,30 @@ func (s *PromptRepoSuite) TestCreateRepoSelectPrompt() {
 	s.Equal(selectP.Templates, selectRepoTemplates, "Should return prompt with the correct templates")
 }
 
+func (s *PromptRepoSuite) TestRepoDetailsTemplate() {
+	lastActivity := time.Date(2020, 3, 14, 0, 0, 0, 0, time.UTC)
+	repo := storage.Repository{
+		Name:          "github.com/TheHipbot/hermes",
+		Description:   "Messenger of the Version Control Gods",
+		DefaultBranch: "master",
+		Language:      "Go",
+		Topics:        []string{"git", "cli"},
+		Visibility:    "public",
+		Stars:         12,
+		LastActivity:  &lastActivity,
+	}
+	tmpl, err := template.New("details").Funcs(selectRepoTemplates.FuncMap).Parse(selectRepoTemplates.Details)
+	s.Nil(err, "Details template should parse")
+	var out bytes.Buffer
+	s.Nil(tmpl.Execute(&out, repo), "Details template should render a repo")
+	s.Contains(out.String(), "Messenger of the Version Control Gods")
+	s.Contains(out.String(), "git, cli", "Topics should be listed")
+	s.Contains(out.String(), "2020-03-14", "Last activity should be shown as a date")
+
+	out.Reset()
+	s.Nil(tmpl.Execute(&out, storage.Repository{Name: "github.com/TheHipbot/dotfiles"}), "Details template should render a repo without details")
+}
+
 func (s *PromptRepoSuite) TestCreateDriverSelectPrompt() {
 	prompter := new(prompterMock)
 	types := []string{
@@ -85,11 +117,11 @@ func (s *PromptRepoSuite) TestCreateDriverSelectPrompt() {
 		"bitbucket",
 	}
 	prompter.
-		On("CreateSelectPrompt", "Select remote server type ", types, selectRepoTemplates).
+		On("CreateSelectPrompt", "Select remote server type ", types, selectDriverTemplates).
 		Return(&promptui.Select{
 			Label:     "Select a repo",
 			Items:     types,
-			Templates: selectRepoTemplates,
+			Templates: selectDriverTemplates,
 		}).
 		Once()
 
@@ -98,7 +130,7 @@ func (s *PromptRepoSuite) TestCreateDriverSelectPrompt() {
 	selectP := res.(*promptui.Select)
 	s.Equal(selectP.Label, "Select a repo", "Should return prompt with the correct label")
 	s.Equal(selectP.Items, types, "Should return prompt with the correct items")
-	s.Equal(selectP.Templates, selectRepoTemplates, "Should return prompt with the correct templates")
+	s.Equal(selectP.Templates, selectDriverTemplates, "Should return prompt with the correct templates")
 }
 
 func (s *PromptRepoSuite) TestCreateTokenInputPrompt() {
@@ -116,6 +148,14 @@ func (s *PromptRepoSuite) TestCreateTokenInputPrompt() {
 	s.Equal(selectP.Label, "Enter auth token", "Should return prompt with the correct label")
 }
 
+func (s *PromptRepoSuite) TestCreatePassphraseInputPrompt() {
+	res := CreatePassphraseInputPrompt(&Prompter{}, "/home/test/.ssh/id_ed25519")
+	s.Equal(&promptui.Prompt{
+		Label: "Enter passphrase for /home/test/.ssh/id_ed25519 ",
+		Mask:  '*',
+	}, res, "Should return a masked prompt for the key")
+}
+
 func TestCacheSuite(t *testing.T) {
 	suite.Run(t, new(PromptRepoSuite))
 }
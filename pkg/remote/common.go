@@ -26,7 +26,7 @@ var (
 	ErrRemoteRequest = errors.New("bad request to remote")
 )
 
-func getRepoHelper(url string, acc []map[string]string, mapper func(map[string]interface{}) map[string]string) ([]map[string]string, error) {
+func getRepoHelper(url string, acc []Repo, mapper func(map[string]interface{}) Repo) ([]Repo, error) {
 	client := &http.Client{Timeout: 30 * time.Second}
 	req, err := http.NewRequest("GET", url, nil)
 	if err != nil {
@@ -4,9 +4,10 @@ package remote
 
 import "errors"
 
-// Driver has GetRepos to find repos from remote
+// Driver has GetRepos and StreamRepos to find repos from remote
 type Driver interface {
-	GetRepos() ([]map[string]string, error)
+	GetRepos() ([]Repo, error)
+	StreamRepos(fn PageFunc) error
 	SetHost(host string)
 	Authenticate(a Auth)
 	AuthType() string
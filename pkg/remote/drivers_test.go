@@ -46,6 +46,32 @@ func (s *DriverSuite) TestRegisterDriver() {
 	s.NotNil(gl, "Should be a gitlab driver")
 }
 
+func (s *DriverSuite) TestCollectRepos() {
+	pages := [][]Repo{
+		{{Name: "github.com/TheHipbot/hermes"}, {Name: "github.com/TheHipbot/dotfiles"}},
+		{{Name: "github.com/TheHipbot/harp"}},
+	}
+	stream := func(fn PageFunc) error {
+		for _, p := range pages {
+			if err := fn(p); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	repos, err := collectRepos(stream)
+	s.Nil(err)
+	s.Len(repos, 3, "Every page should be collected")
+
+	failing := func(fn PageFunc) error {
+		fn(pages[0])
+		return ErrAuth
+	}
+	repos, err = collectRepos(failing)
+	s.Equal(ErrAuth, err, "Stream errors should be returned")
+	s.Len(repos, 2, "Pages before the error should be returned")
+}
+
 func TestDriverSuite(t *testing.T) {
 	suite.Run(t, new(DriverSuite))
 }
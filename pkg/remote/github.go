@@ -64,8 +64,12 @@ func (gh *GitHub) AuthType() string {
 }
 
 // GetRepos gets the repos for the github user
-func (gh *GitHub) GetRepos() ([]map[string]string, error) {
-	allRepos := []map[string]string{}
+func (gh *GitHub) GetRepos() ([]Repo, error) {
+	return collectRepos(gh.StreamRepos)
+}
+
+// StreamRepos gets the repos for the github user a page at a time
+func (gh *GitHub) StreamRepos(fn PageFunc) error {
 	opts := &github.RepositoryListOptions{
 		ListOptions: github.ListOptions{
 			PerPage: 40,
@@ -75,11 +79,10 @@ func (gh *GitHub) GetRepos() ([]map[string]string, error) {
 	for {
 		repos, resp, err := gh.client.Repositories.List(context.Background(), "", opts)
 		if err != nil {
-			return allRepos, err
+			return err
 		}
-		allRepos, err = mapGitHubRepos(allRepos, repos)
-		if err != nil {
-			return allRepos, err
+		if err := fn(mapGitHubRepos(repos)); err != nil {
+			return err
 		}
 		if resp.NextPage == 0 {
 			break
@@ -87,17 +90,32 @@ func (gh *GitHub) GetRepos() ([]map[string]string, error) {
 		opts.Page = resp.NextPage
 	}
 
-	return allRepos, nil
+	return nil
 }
 
-func mapGitHubRepos(acc []map[string]string, repos []*github.Repository) ([]map[string]string, error) {
+func mapGitHubRepos(repos []*github.Repository) []Repo {
+	page := make([]Repo, 0, len(repos))
 	for _, r := range repos {
-		entry := make(map[string]string, 4)
-		entry["url"] = r.GetHTMLURL()
-		entry["name"] = strings.Split(entry["url"], "://")[1]
-		entry["clone_url"] = r.GetCloneURL()
-		entry["ssh_url"] = r.GetSSHURL()
-		acc = append(acc, entry)
+		entry := Repo{
+			URL:           r.GetHTMLURL(),
+			CloneURL:      r.GetCloneURL(),
+			SSHURL:        r.GetSSHURL(),
+			Description:   r.GetDescription(),
+			DefaultBranch: r.GetDefaultBranch(),
+			Language:      r.GetLanguage(),
+			Topics:        r.Topics,
+			Visibility:    "public",
+			Archived:      r.GetArchived(),
+			Stars:         r.GetStargazersCount(),
+		}
+		entry.Name = strings.Split(entry.URL, "://")[1]
+		if r.GetPrivate() {
+			entry.Visibility = "private"
+		}
+		if pushedAt := r.GetPushedAt(); !pushedAt.IsZero() {
+			entry.LastActivity = &pushedAt.Time
+		}
+		page = append(page, entry)
 	}
-	return acc, nil
+	return page
 }
@@ -3,6 +3,7 @@ package remote
 import (
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/google/go-github/v29/github"
 	"github.com/stretchr/testify/suite"
@@ -75,8 +76,6 @@ func (s *GitHubRemoteSuite) TestGitHubSetAuth() {
 }
 
 func (s *GitHubRemoteSuite) TestGithubMapper() {
-	res := []map[string]string{}
-
 	htmlURL1 := "https://github.com/carsdotcom/beacon"
 	cloneURL1 := "https://github.com/carsdotcom/beacon.git"
 	sshURL1 := "git@github.com:carsdotcom/beacon.git"
@@ -95,16 +94,15 @@ func (s *GitHubRemoteSuite) TestGithubMapper() {
 			SSHURL:   &sshURL2,
 		},
 	}
-	res, err := mapGitHubRepos(res, testRepos)
-	s.Nil(err)
-	s.Equal(res[0]["url"], htmlURL1)
-	s.Equal(res[0]["name"], strings.Split(htmlURL1, "://")[1])
-	s.Equal(res[0]["ssh_url"], sshURL1)
-	s.Equal(res[0]["clone_url"], cloneURL1)
-	s.Equal(res[1]["url"], htmlURL2)
-	s.Equal(res[1]["name"], strings.Split(htmlURL2, "://")[1])
-	s.Equal(res[1]["ssh_url"], sshURL2)
-	s.Equal(res[1]["clone_url"], cloneURL2)
+	res := mapGitHubRepos(testRepos)
+	s.Equal(res[0].URL, htmlURL1)
+	s.Equal(res[0].Name, strings.Split(htmlURL1, "://")[1])
+	s.Equal(res[0].SSHURL, sshURL1)
+	s.Equal(res[0].CloneURL, cloneURL1)
+	s.Equal(res[1].URL, htmlURL2)
+	s.Equal(res[1].Name, strings.Split(htmlURL2, "://")[1])
+	s.Equal(res[1].SSHURL, sshURL2)
+	s.Equal(res[1].CloneURL, cloneURL2)
 }
 
 func (s *GitHubRemoteSuite) TestGitHubAuthType() {
@@ -119,6 +117,44 @@ func (s *GitHubRemoteSuite) TestGitHubAuthType() {
 	s.Equal(authToken, d.AuthType(), "AuthType should be authToken")
 }
 
+func (s *GitHubRemoteSuite) TestGithubMapperDetails() {
+	htmlURL := "https://github.com/TheHipbot/hermes"
+	description := "Messenger of the Version Control Gods"
+	branch := "master"
+	language := "Go"
+	private := true
+	archived := true
+	stars := 12
+	pushedAt := time.Date(2020, 3, 14, 15, 9, 26, 0, time.UTC)
+	testRepos := []*github.Repository{
+		&github.Repository{
+			HTMLURL:         &htmlURL,
+			Description:     &description,
+			DefaultBranch:   &branch,
+			Language:        &language,
+			Topics:          []string{"git", "cli"},
+			Private:         &private,
+			Archived:        &archived,
+			StargazersCount: &stars,
+			PushedAt:        &github.Timestamp{Time: pushedAt},
+		},
+		&github.Repository{
+			HTMLURL: &htmlURL,
+		},
+	}
+	res := mapGitHubRepos(testRepos)
+	s.Equal(description, res[0].Description)
+	s.Equal(branch, res[0].DefaultBranch)
+	s.Equal(language, res[0].Language)
+	s.Equal([]string{"git", "cli"}, res[0].Topics)
+	s.Equal("private", res[0].Visibility)
+	s.True(res[0].Archived)
+	s.Equal(12, res[0].Stars)
+	s.Equal(pushedAt, *res[0].LastActivity)
+	s.Equal("public", res[1].Visibility, "Repos which are not private should be public")
+	s.Nil(res[1].LastActivity, "Repos never pushed to should have no last activity")
+}
+
 func TestGitHubRemoteSuite(t *testing.T) {
 	suite.Run(t, new(GitHubRemoteSuite))
 }
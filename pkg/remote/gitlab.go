@@ -54,9 +54,13 @@ func (gl *GitLab) AuthType() string {
 	return authToken
 }
 
-// GetRepos gets the repos for the github user
-func (gl *GitLab) GetRepos() ([]map[string]string, error) {
-	allRepos := []map[string]string{}
+// GetRepos gets the repos for the gitlab user
+func (gl *GitLab) GetRepos() ([]Repo, error) {
+	return collectRepos(gl.StreamRepos)
+}
+
+// StreamRepos gets the repos for the gitlab user a page at a time
+func (gl *GitLab) StreamRepos(fn PageFunc) error {
 	membership := !gl.Opts.AllRepos
 	opts := &gitlab.ListProjectsOptions{
 		Membership: &membership,
@@ -66,17 +70,16 @@ func (gl *GitLab) GetRepos() ([]map[string]string, error) {
 	}
 
 	if gl.Auth.Token == "" && gl.Auth.Username == "" {
-		return nil, ErrAuth
+		return ErrAuth
 	}
 
 	for {
 		projects, resp, err := gl.client.Projects.ListProjects(opts)
 		if err != nil {
-			return allRepos, err
+			return err
 		}
-		allRepos, err = mapGitLabProjects(allRepos, projects)
-		if err != nil {
-			return allRepos, err
+		if err := fn(mapGitLabProjects(projects)); err != nil {
+			return err
 		}
 		if resp.NextPage == 0 {
 			break
@@ -84,17 +87,25 @@ func (gl *GitLab) GetRepos() ([]map[string]string, error) {
 		opts.Page = resp.NextPage
 	}
 
-	return allRepos, nil
+	return nil
 }
 
-func mapGitLabProjects(acc []map[string]string, projects []*gitlab.Project) ([]map[string]string, error) {
+func mapGitLabProjects(projects []*gitlab.Project) []Repo {
+	page := make([]Repo, 0, len(projects))
 	for _, p := range projects {
-		entry := make(map[string]string, 4)
-		entry["url"] = p.WebURL
-		entry["name"] = strings.Split(entry["url"], "://")[1]
-		entry["clone_url"] = p.HTTPURLToRepo
-		entry["ssh_url"] = p.SSHURLToRepo
-		acc = append(acc, entry)
+		page = append(page, Repo{
+			Name:          strings.Split(p.WebURL, "://")[1],
+			URL:           p.WebURL,
+			CloneURL:      p.HTTPURLToRepo,
+			SSHURL:        p.SSHURLToRepo,
+			Description:   p.Description,
+			DefaultBranch: p.DefaultBranch,
+			Topics:        p.TagList,
+			Visibility:    string(p.Visibility),
+			Archived:      p.Archived,
+			Stars:         p.StarCount,
+			LastActivity:  p.LastActivityAt,
+		})
 	}
-	return acc, nil
+	return page
 }
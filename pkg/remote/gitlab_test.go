@@ -3,6 +3,7 @@ package remote
 import (
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/suite"
 	gitlab "github.com/xanzy/go-gitlab"
@@ -70,8 +71,6 @@ func (s *GitLabRemoteSuite) TestGitlabAuthType() {
 }
 
 func (s *GitLabRemoteSuite) TestGitLabMapper() {
-	res := []map[string]string{}
-
 	htmlURL1 := "https://github.com/carsdotcom/beacon"
 	cloneURL1 := "https://github.com/carsdotcom/beacon.git"
 	sshURL1 := "git@github.com:carsdotcom/beacon.git"
@@ -90,16 +89,38 @@ func (s *GitLabRemoteSuite) TestGitLabMapper() {
 			SSHURLToRepo:  sshURL2,
 		},
 	}
-	res, err := mapGitLabProjects(res, testRepos)
-	s.Nil(err)
-	s.Equal(res[0]["url"], htmlURL1)
-	s.Equal(res[0]["name"], strings.Split(htmlURL1, "://")[1])
-	s.Equal(res[0]["ssh_url"], sshURL1)
-	s.Equal(res[0]["clone_url"], cloneURL1)
-	s.Equal(res[1]["url"], htmlURL2)
-	s.Equal(res[1]["name"], strings.Split(htmlURL2, "://")[1])
-	s.Equal(res[1]["ssh_url"], sshURL2)
-	s.Equal(res[1]["clone_url"], cloneURL2)
+	res := mapGitLabProjects(testRepos)
+	s.Equal(res[0].URL, htmlURL1)
+	s.Equal(res[0].Name, strings.Split(htmlURL1, "://")[1])
+	s.Equal(res[0].SSHURL, sshURL1)
+	s.Equal(res[0].CloneURL, cloneURL1)
+	s.Equal(res[1].URL, htmlURL2)
+	s.Equal(res[1].Name, strings.Split(htmlURL2, "://")[1])
+	s.Equal(res[1].SSHURL, sshURL2)
+	s.Equal(res[1].CloneURL, cloneURL2)
+}
+
+func (s *GitLabRemoteSuite) TestGitLabMapperDetails() {
+	lastActivity := time.Date(2020, 3, 14, 15, 9, 26, 0, time.UTC)
+	testRepos := []*gitlab.Project{
+		&gitlab.Project{
+			WebURL:         "https://gitlab.com/gnachman/iterm2",
+			Description:    "iTerm2 is a terminal emulator for Mac OS X",
+			DefaultBranch:  "master",
+			TagList:        []string{"terminal"},
+			Visibility:     gitlab.InternalVisibility,
+			StarCount:      7,
+			LastActivityAt: &lastActivity,
+		},
+	}
+	res := mapGitLabProjects(testRepos)
+	s.Equal("iTerm2 is a terminal emulator for Mac OS X", res[0].Description)
+	s.Equal("master", res[0].DefaultBranch)
+	s.Equal([]string{"terminal"}, res[0].Topics)
+	s.Equal("internal", res[0].Visibility)
+	s.False(res[0].Archived)
+	s.Equal(7, res[0].Stars)
+	s.Equal(&lastActivity, res[0].LastActivity)
 }
 
 func TestGitLabRemoteSuite(t *testing.T) {
@@ -0,0 +1,35 @@
+package remote
+
+import "time"
+
+// Repo is a repository found on a remote
+type Repo struct {
+	// Name is the repo's web URL without its scheme,
+	// e.g. github.com/TheHipbot/hermes
+	Name          string
+	URL           string
+	CloneURL      string
+	SSHURL        string
+	Description   string
+	DefaultBranch string
+	Language      string
+	Topics        []string
+	Visibility    string
+	Archived      bool
+	Stars         int
+	LastActivity  *time.Time
+}
+
+// PageFunc is called by Driver.StreamRepos with each page of repos
+// as it is fetched, returning an error stops the stream
+type PageFunc func(page []Repo) error
+
+// collectRepos streams every page of repos into a single slice
+func collectRepos(stream func(fn PageFunc) error) ([]Repo, error) {
+	allRepos := []Repo{}
+	err := stream(func(page []Repo) error {
+		allRepos = append(allRepos, page...)
+		return nil
+	})
+	return allRepos, err
+}
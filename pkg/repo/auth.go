@@ -0,0 +1,46 @@
+package repo
+
+import (
+	githttp "gopkg.in/src-d/go-git.v4/plumbing/transport/http"
+)
+
+// credentialHelper gives git the username and token in the environment
+// when it asks for credentials, and stores or erases nothing
+const credentialHelper = `!f() { test "$1" = get && echo "username=$HERMES_GIT_USERNAME" && echo "password=$HERMES_GIT_TOKEN"; }; f`
+
+// newHTTPAuth creates the auth for https connections with a token
+func newHTTPAuth(username, token string) AuthMethod {
+	return &githttp.BasicAuth{
+		Username: username,
+		Password: token,
+	}
+}
+
+// finishAuth is called with the error of the operation using the auth once
+// it is done, to release what the auth holds and return why it failed
+func finishAuth(auth AuthMethod, err error) error {
+	if a, ok := auth.(*sshAuthMethod); ok {
+		return a.finish(err)
+	}
+	return err
+}
+
+// gitAuth returns the options and environment which authenticate git
+// with the https auth. The token is given through a credential helper
+// replacing any others, so it is never in git's arguments, the URL
+// written to the repo's git config or another helper's store
+func gitAuth(auth AuthMethod) ([]string, []string) {
+	basic, ok := auth.(*githttp.BasicAuth)
+	if !ok {
+		return nil, nil
+	}
+	args := []string{
+		"-c", "credential.helper=",
+		"-c", "credential.helper=" + credentialHelper,
+	}
+	env := []string{
+		"HERMES_GIT_USERNAME=" + basic.Username,
+		"HERMES_GIT_TOKEN=" + basic.Password,
+	}
+	return args, env
+}
@@ -0,0 +1,123 @@
+package repo
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/cgi"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	githttp "gopkg.in/src-d/go-git.v4/plumbing/transport/http"
+)
+
+const testToken = "glpat-s3cr3t"
+
+type AuthSuite struct {
+	suite.Suite
+	dir    string
+	server *httptest.Server
+}
+
+// newAuthGitServer serves the repos in dir over http with git http-backend,
+// only when they are asked for with the token
+func newAuthGitServer(dir, token string) (*httptest.Server, error) {
+	execPath, err := exec.Command("git", "--exec-path").Output()
+	if err != nil {
+		return nil, err
+	}
+	backend := &cgi.Handler{
+		Path: filepath.Join(strings.TrimSpace(string(execPath)), "git-http-backend"),
+		Env:  []string{"GIT_PROJECT_ROOT=" + dir, "GIT_HTTP_EXPORT_ALL=1"},
+	}
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, password, ok := r.BasicAuth(); !ok || password != token {
+			w.Header().Set("WWW-Authenticate", `Basic realm="hermes"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		backend.ServeHTTP(w, r)
+	})), nil
+}
+
+// initBareRepo creates the bare repo dir/name.git with a commit in it
+func initBareRepo(dir, name string) error {
+	work := filepath.Join(dir, name)
+	for _, args := range [][]string{
+		{"init", "--quiet", work},
+		{"-C", work, "-c", "user.name=hermes", "-c", "user.email=hermes@example.com", "commit", "--quiet", "--allow-empty", "-m", "init"},
+		{"clone", "--quiet", "--bare", work, filepath.Join(dir, name+".git")},
+	} {
+		if out, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+			return errors.New(string(out))
+		}
+	}
+	return nil
+}
+
+func (suite *AuthSuite) SetupTest() {
+	var err error
+	suite.dir, err = ioutil.TempDir("", "hermes-auth")
+	suite.Nil(err)
+	suite.Nil(initBareRepo(suite.dir, "hermes"))
+	suite.server, err = newAuthGitServer(suite.dir, testToken)
+	suite.Nil(err)
+}
+
+func (suite *AuthSuite) TearDownTest() {
+	suite.server.Close()
+	os.RemoveAll(suite.dir)
+}
+
+func (suite *AuthSuite) TestAuth() {
+	gr := NewGitRepository("github.com/TheHipbot/hermes", "https://github.com/TheHipbot/hermes")
+	auth, err := gr.auth()
+	suite.Nil(err)
+	suite.Nil(auth, "Without a token there should be no auth")
+
+	gr.Username = "oauth2"
+	gr.Token = testToken
+	auth, err = gr.auth()
+	suite.Nil(err)
+	suite.Equal(&githttp.BasicAuth{Username: "oauth2", Password: testToken}, auth)
+	suite.NotContains(auth.String(), testToken, "The token should not be printed")
+
+	gr.URL = "http://github.com/TheHipbot/hermes"
+	auth, err = gr.auth()
+	suite.Nil(err)
+	suite.Nil(auth, "The token should only be sent over https")
+}
+
+func (suite *AuthSuite) TestGitAuth() {
+	args, env := gitAuth(nil)
+	suite.Empty(args)
+	suite.Empty(env)
+
+	args, env = gitAuth(newHTTPAuth("oauth2", testToken))
+	suite.NotContains(strings.Join(args, " "), testToken, "The token should not be in git's arguments")
+
+	url := suite.server.URL + "/hermes.git"
+	path := filepath.Join(suite.dir, "clone")
+	cmd := exec.Command("git", append(args, "clone", "--quiet", url, path)...)
+	cmd.Env = append(os.Environ(), append(env, "GIT_TERMINAL_PROMPT=0")...)
+	out, err := cmd.CombinedOutput()
+	suite.Nil(err, string(out))
+
+	config, err := ioutil.ReadFile(filepath.Join(path, ".git", "config"))
+	suite.Nil(err)
+	suite.Contains(string(config), url, "origin should be the URL")
+	suite.NotContains(string(config), testToken, "The token should not be written to the git config")
+
+	cmd = exec.Command("git", "clone", "--quiet", url, filepath.Join(suite.dir, "unauthenticated"))
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	suite.NotNil(cmd.Run(), "The clone should need the token")
+}
+
+func TestAuthSuite(t *testing.T) {
+	suite.Run(t, new(AuthSuite))
+}
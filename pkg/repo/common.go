@@ -14,6 +14,35 @@ var (
 	ErrRepoAlreadyExists = errors.New("repository already exists")
 	// ErrCloneRepo when there is a normal error cloning repo
 	ErrCloneRepo = errors.New("error cloning repo")
+	// ErrPullRepo when a repo could not be pulled, such as when its branch
+	// has diverged from upstream and cannot be fast-forwarded
+	ErrPullRepo = errors.New("error pulling repo")
+	// ErrFetchRepo when a repo could not be fetched from its remote
+	ErrFetchRepo = errors.New("error fetching repo")
+	// ErrUnsupportedCloneOption when a cloner cannot clone with
+	// one of the given options
+	ErrUnsupportedCloneOption = errors.New("clone option is not supported by this cloner")
+	// ErrConfigRepo when the git config of a repo could not be set
+	ErrConfigRepo = errors.New("error setting repo git config")
+	// ErrNoSSHKey when there are no keys in ssh-agent or ~/.ssh to authenticate with
+	ErrNoSSHKey = errors.New("no ssh key found in ssh-agent or ~/.ssh")
+	// ErrUnknownHostKey when the ssh host has no keys in known_hosts
+	ErrUnknownHostKey = errors.New("ssh host key is not in known_hosts")
+	// ErrHostKeyMismatch when the key of the ssh host is not the one in known_hosts
+	ErrHostKeyMismatch = errors.New("ssh host key does not match known_hosts")
+	// ErrAuthFailed when git could not authenticate to the remote
+	ErrAuthFailed = errors.New("authentication failed")
+	// ErrRepoNotFound when the remote has no repo at the URL, or it
+	// is private and the credentials have no access to it
+	ErrRepoNotFound = errors.New("repository not found")
+	// ErrHostUnreachable when the host of the remote could not be connected to
+	ErrHostUnreachable = errors.New("host unreachable")
+	// ErrHostKeyVerification when the key of the ssh host could not be verified
+	ErrHostKeyVerification = errors.New("host key verification failed")
+	// ErrDiskFull when there is no space left to write the clone
+	ErrDiskFull = errors.New("no space left on device")
+	// ErrPermissionDenied when the clone could not be written to its path
+	ErrPermissionDenied = errors.New("permission denied")
 )
 
 // Repository struct holds information for a repository
@@ -28,16 +57,125 @@ type AuthMethod interface {
 	fmt.Stringer
 }
 
+// ProgressReporter is given the progress of clones
+type ProgressReporter interface {
+	Progress(event ProgressEvent)
+}
+
+// ProgressEvent is a step in the progress of a clone, parsed from git's
+// progress output, or a message git printed which is not progress
+type ProgressEvent struct {
+	// Phase is what git is doing, such as Receiving objects
+	Phase   string `json:"phase,omitempty"`
+	Percent int    `json:"percent"`
+	// Objects is the number of objects done in the phase
+	// out of Total, which is 0 when git does not know it
+	Objects int    `json:"objects"`
+	Total   int    `json:"total,omitempty"`
+	Bytes   int64  `json:"bytes,omitempty"`
+	Done    bool   `json:"done,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
 // CloneOptions is for packaging various
 // options for cloning repositories
 type CloneOptions struct {
 	URL  string
 	Auth AuthMethod
+	// Depth limits the history cloned to the given number
+	// of commits, all of it is cloned when 0
+	Depth int
+	// SingleBranch only fetches the branch which is checked out
+	SingleBranch bool
+	// Reference is the branch or tag to check out instead
+	// of the default branch
+	Reference string
+	// Filter is a partial clone filter, such as blob:none
+	Filter string
+	// SparsePaths are the only directories checked out,
+	// along with files in the root of the repo
+	SparsePaths []string
+	// Submodules clones the submodules of the repo, and theirs
+	Submodules bool
+	// SubmoduleDepth limits how many levels of nested submodules
+	// are cloned, all of them are cloned when 0
+	SubmoduleDepth int
+	// SubmoduleJobs is the number of submodules fetched at once
+	SubmoduleJobs int
+	// LFS fetches the Git LFS files of repos which use LFS
+	LFS bool
+	// GitConfig is written to the local git config of the
+	// clone, keyed by name such as user.email
+	GitConfig map[string]string
+	// Progress is given the progress of the clone,
+	// nothing is reported when it is nil
+	Progress ProgressReporter
+}
+
+// PullOptions is for packaging various
+// options for pulling repositories
+type PullOptions struct {
+	Auth AuthMethod
+}
+
+// SyncOptions is for packaging various
+// options for syncing repositories
+type SyncOptions struct {
+	Auth AuthMethod
+}
+
+// SyncResult describes how the current branch of a clone
+// compares to its upstream once it has been synced
+type SyncResult struct {
+	// Branch is empty when HEAD is detached
+	Branch     string
+	NoUpstream bool
+	Dirty      bool
+	Ahead      int
+	Behind     int
+	// Updated is set when the branch was fast-forwarded
+	// by the Behind commits
+	Updated bool
+}
+
+// CanFastForward reports whether the branch is behind its upstream
+// and can be fast-forwarded without losing any work
+func (r *SyncResult) CanFastForward() bool {
+	return !r.Dirty && r.Ahead == 0 && r.Behind > 0
+}
+
+func (r *SyncResult) String() string {
+	switch {
+	case r.Branch == "":
+		return "detached HEAD, not pulled"
+	case r.NoUpstream:
+		return fmt.Sprintf("%s has no upstream, not pulled", r.Branch)
+	case r.Updated:
+		return fmt.Sprintf("%s fast-forwarded %d commits", r.Branch, r.Behind)
+	}
+
+	state := "up to date"
+	switch {
+	case r.Ahead > 0 && r.Behind > 0:
+		state = fmt.Sprintf("diverged, %d ahead and %d behind", r.Ahead, r.Behind)
+	case r.Ahead > 0:
+		state = fmt.Sprintf("%d ahead", r.Ahead)
+	case r.Behind > 0:
+		state = fmt.Sprintf("%d behind", r.Behind)
+	}
+	if r.Dirty {
+		state += ", dirty, not pulled"
+	}
+	return fmt.Sprintf("%s %s", r.Branch, state)
 }
 
-// Cloner is an interface for cloning repositories
+// Cloner is an interface for cloning repositories and
+// keeping clones up to date with their upstream
 type Cloner interface {
 	Clone(path string, opts *CloneOptions) error
+	Pull(path string, opts *PullOptions) error
+	Sync(path string, opts *SyncOptions) (*SyncResult, error)
+	Configure(path string, config map[string]string) error
 }
 
 // RegisterCloner takes a name for the cloner type and a function
@@ -16,6 +16,18 @@ func (*clonerSuiteCloner) Clone(path string, opts *CloneOptions) error {
 	return nil
 }
 
+func (*clonerSuiteCloner) Pull(path string, opts *PullOptions) error {
+	return nil
+}
+
+func (*clonerSuiteCloner) Sync(path string, opts *SyncOptions) (*SyncResult, error) {
+	return &SyncResult{}, nil
+}
+
+func (*clonerSuiteCloner) Configure(path string, config map[string]string) error {
+	return nil
+}
+
 func (suite *ClonerSuite) TestRegisterCloner() {
 	testCloner := &clonerSuiteCloner{}
 	testCreator := func() (Cloner, error) {
@@ -40,6 +52,23 @@ func (suite *ClonerSuite) TestNewCloner() {
 	suite.Equal(c, testCloner)
 }
 
+func (suite *ClonerSuite) TestSyncResult() {
+	behind := &SyncResult{Branch: "main", Behind: 2}
+	suite.True(behind.CanFastForward())
+	suite.Equal("main 2 behind", behind.String())
+
+	behind.Dirty = true
+	suite.False(behind.CanFastForward(), "A dirty tree should not be pulled")
+	suite.Equal("main 2 behind, dirty, not pulled", behind.String())
+
+	diverged := &SyncResult{Branch: "main", Ahead: 1, Behind: 2}
+	suite.False(diverged.CanFastForward(), "A diverged branch should not be pulled")
+	suite.Equal("main diverged, 1 ahead and 2 behind", diverged.String())
+
+	suite.Equal("main fast-forwarded 3 commits", (&SyncResult{Branch: "main", Behind: 3, Updated: true}).String())
+	suite.Equal("detached HEAD, not pulled", (&SyncResult{}).String())
+}
+
 func TestClonerSuite(t *testing.T) {
 	suite.Run(t, new(ClonerSuite))
 }
@@ -0,0 +1,49 @@
+package repo
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// cloneErrors classify why a clone failed from the messages printed by
+// git, ssh or go-git. They are checked in order, so the first one
+// matching is the cause, e.g. ssh's Permission denied (publickey)
+// is an authentication failure rather than a permission error
+var cloneErrors = []struct {
+	err     error
+	pattern *regexp.Regexp
+}{
+	{ErrHostKeyVerification, regexp.MustCompile(`(?i)host key verification failed|remote host identification has changed|` +
+		regexp.QuoteMeta(ErrUnknownHostKey.Error()) + `|` + regexp.QuoteMeta(ErrHostKeyMismatch.Error()))},
+	{ErrAuthFailed, regexp.MustCompile(`(?i)authentication (failed|required)|authorization failed|permission denied \(|` +
+		`could not read (username|password)|invalid username or password|unable to authenticate|` +
+		`access denied|returned error: 40[13]|` + regexp.QuoteMeta(ErrNoSSHKey.Error()))},
+	{ErrRepoNotFound, regexp.MustCompile(`(?i)repository (.+ )?not found|does not appear to be a git repository|returned error: 404`)},
+	{ErrHostUnreachable, regexp.MustCompile(`(?i)could not resolve host|no such host|connection refused|timed out|` +
+		`network is unreachable|no route to host|failed to connect to`)},
+	{ErrDiskFull, regexp.MustCompile(`(?i)no space left on device|disk quota exceeded`)},
+	{ErrPermissionDenied, regexp.MustCompile(`(?i)permission denied|read-only file system`)},
+}
+
+// classifyOutput returns the error for the lines a failed clone printed,
+// wrapping the line which gave its cause, or ErrCloneRepo with the
+// message when the cause is unknown
+func classifyOutput(lines []string, message string) error {
+	for _, class := range cloneErrors {
+		for _, line := range lines {
+			if class.pattern.MatchString(line) {
+				return fmt.Errorf("%w: %s", class.err, trimGitPrefix(line))
+			}
+		}
+	}
+	return fmt.Errorf("%w: %s", ErrCloneRepo, message)
+}
+
+// trimGitPrefix removes the prefix git gives a line of its output
+func trimGitPrefix(line string) string {
+	for _, prefix := range []string{"fatal:", "error:", "remote:"} {
+		line = strings.TrimPrefix(line, prefix)
+	}
+	return strings.TrimSpace(line)
+}
@@ -0,0 +1,89 @@
+package repo
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type ErrorsSuite struct {
+	suite.Suite
+}
+
+func (s *ErrorsSuite) TestClassifyOutput() {
+	cases := []struct {
+		output  string
+		err     error
+		message string
+	}{
+		{
+			"git@github.com: Permission denied (publickey).\nfatal: Could not read from remote repository.\n\nPlease make sure you have the correct access rights\nand the repository exists.",
+			ErrAuthFailed,
+			"git@github.com: Permission denied (publickey).",
+		},
+		{
+			"remote: HTTP Basic: Access denied\nfatal: Authentication failed for 'https://gitlab.com/TheHipbot/weather.git/'",
+			ErrAuthFailed,
+			"HTTP Basic: Access denied",
+		},
+		{
+			"fatal: could not read Username for 'https://github.com': terminal prompts disabled",
+			ErrAuthFailed,
+			"could not read Username for 'https://github.com': terminal prompts disabled",
+		},
+		{
+			"remote: Repository not found.\nfatal: repository 'https://github.com/TheHipbot/nope/' not found",
+			ErrRepoNotFound,
+			"Repository not found.",
+		},
+		{
+			"fatal: unable to access 'https://github.example.com/TheHipbot/hermes/': Could not resolve host: github.example.com",
+			ErrHostUnreachable,
+			"unable to access 'https://github.example.com/TheHipbot/hermes/': Could not resolve host: github.example.com",
+		},
+		{
+			"ssh: connect to host github.com port 22: Connection timed out\nfatal: Could not read from remote repository.",
+			ErrHostUnreachable,
+			"ssh: connect to host github.com port 22: Connection timed out",
+		},
+		{
+			"Host key verification failed.\nfatal: Could not read from remote repository.",
+			ErrHostKeyVerification,
+			"Host key verification failed.",
+		},
+		{
+			"error: unable to write file README.md: No space left on device\nfatal: unable to checkout working tree",
+			ErrDiskFull,
+			"unable to write file README.md: No space left on device",
+		},
+		{
+			"fatal: could not create work tree dir 'hermes': Permission denied",
+			ErrPermissionDenied,
+			"could not create work tree dir 'hermes': Permission denied",
+		},
+		{
+			"fatal: the remote end hung up unexpectedly",
+			ErrCloneRepo,
+			"the remote end hung up",
+		},
+	}
+	for _, c := range cases {
+		err := classifyOutput(strings.Split(c.output, "\n"), "the remote end hung up")
+		s.True(errors.Is(err, c.err), "%q should be %s, got %s", c.output, c.err, err)
+		s.Equal(c.err.Error()+": "+c.message, err.Error(), "The line giving the cause should be kept")
+	}
+}
+
+func (s *ErrorsSuite) TestClassifySSHErrors() {
+	err := classifyOutput([]string{"ssh: handshake failed: " + ErrNoSSHKey.Error()}, "")
+	s.True(errors.Is(err, ErrAuthFailed), "No ssh key should be an authentication failure")
+
+	err = classifyOutput([]string{"ssh: handshake failed: " + ErrHostKeyMismatch.Error() + " for github.com:22"}, "")
+	s.True(errors.Is(err, ErrHostKeyVerification), "A changed host key should fail verification")
+}
+
+func TestErrorsSuite(t *testing.T) {
+	suite.Run(t, new(ErrorsSuite))
+}
@@ -1,16 +1,12 @@
 package repo
 
 import (
-	"errors"
-	"os"
-	"regexp"
+	"sort"
+	"strings"
 
-	"github.com/kevinburke/ssh_config"
-	homedir "github.com/mitchellh/go-homedir"
-	"golang.org/x/crypto/ssh"
+	"github.com/TheHipbot/hermes/pkg/prompt"
 	billy "gopkg.in/src-d/go-billy.v4"
 	"gopkg.in/src-d/go-billy.v4/osfs"
-	"gopkg.in/src-d/go-git.v4/plumbing/transport"
 	sshgit "gopkg.in/src-d/go-git.v4/plumbing/transport/ssh"
 )
 
@@ -21,6 +17,7 @@ var (
 
 func init() {
 	appFs = osfs.New("")
+	sshgit.DefaultSSHConfig = sshConfig{}
 }
 
 // GitRepository holds info for git repos and
@@ -31,6 +28,15 @@ type GitRepository struct {
 	URL      string
 	Protocol string
 	Cloner   Cloner
+	// Prompter asks for the passphrases of ssh keys
+	Prompter prompt.Factory
+	// Username and Token authenticate https connections, the
+	// token is never written to the repository's git config
+	Username string
+	Token    string
+	// CloneOptions are the options used by Clone,
+	// its URL and Auth are set from the repository
+	CloneOptions CloneOptions
 }
 
 // NewGitRepository creates a GitRepository
@@ -47,60 +53,70 @@ func NewGitRepository(name, url string) *GitRepository {
 // Clone git repository to path
 func (gr *GitRepository) Clone(path string) error {
 
-	opts := &CloneOptions{
-		URL: gr.URL,
-	}
+	opts := gr.CloneOptions
+	opts.URL = gr.URL
 
-	switch gr.Protocol {
-	case "ssh":
-		var hostname string
-		r, err := regexp.Compile(`.+@([a-zA-z.\-0-9]+)[:/].+`)
-		if err != nil {
-			return errors.New("regular expression should compile")
-		}
-		cps := r.FindStringSubmatch(gr.URL)
-		if len(cps) < 2 {
-			return errors.New("could not find hostname in URL")
-		}
-		hostname = cps[1]
-		a, err := getSSHAuth(hostname)
-		opts.Auth = a
+	a, err := gr.auth()
+	if err != nil {
+		return err
 	}
+	opts.Auth = a
 
-	err := gr.Cloner.Clone(path, opts)
-	return err
+	return finishAuth(a, gr.Cloner.Clone(path, &opts))
 }
 
-func getSSHAuth(host string) (transport.AuthMethod, error) {
-	pathsToCheck := []string{
-		ssh_config.Get(host, "IdentityFile"),
-		"~/.ssh/id_rsa",
-		"/etc/ssh/id_rsa",
+// Pull fast-forwards the git repository at path to its upstream
+func (gr *GitRepository) Pull(path string) error {
+	a, err := gr.auth()
+	if err != nil {
+		return err
 	}
-
-	for _, path := range pathsToCheck {
-		if keyPath, err := pathIfExists(path); err == nil {
-			pk, err := sshgit.NewPublicKeysFromFile("git", keyPath, "")
-			if err != nil {
-				return &sshgit.PublicKeys{}, err
-			}
-			pk.HostKeyCallbackHelper = sshgit.HostKeyCallbackHelper{
-				HostKeyCallback: ssh.InsecureIgnoreHostKey(),
-			}
-			return pk, nil
-		}
+	opts := &PullOptions{
+		Auth: a,
 	}
 
-	return nil, errors.New("no ssh key found")
+	return finishAuth(a, gr.Cloner.Pull(path, opts))
 }
 
-func pathIfExists(path string) (string, error) {
-	keyPath, err := homedir.Expand(path)
+// Sync fetches the git repository at path and fast-forwards its current
+// branch when it is behind its upstream and has no local changes
+func (gr *GitRepository) Sync(path string) (*SyncResult, error) {
+	a, err := gr.auth()
 	if err != nil {
-		return "", err
+		return nil, err
+	}
+	opts := &SyncOptions{
+		Auth: a,
+	}
+
+	res, err := gr.Cloner.Sync(path, opts)
+	return res, finishAuth(a, err)
+}
+
+// Configure sets the entries in the local git config of the git repository at path
+func (gr *GitRepository) Configure(path string, config map[string]string) error {
+	return gr.Cloner.Configure(path, config)
+}
+
+// SortedKeys returns the keys of the git config in order, so
+// entries are always written and shown the same way
+func SortedKeys(config map[string]string) []string {
+	keys := make([]string, 0, len(config))
+	for key := range config {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// auth returns how to authenticate with the host in the repository URL,
+// tokens are only sent over https
+func (gr *GitRepository) auth() (AuthMethod, error) {
+	if gr.Protocol == "ssh" {
+		return newSSHAuth(gr.URL, gr.Prompter)
 	}
-	if _, err := appFs.Stat(keyPath); os.IsNotExist(err) {
-		return "", err
+	if gr.Token != "" && strings.HasPrefix(gr.URL, "https://") {
+		return newHTTPAuth(gr.Username, gr.Token), nil
 	}
-	return keyPath, nil
+	return nil, nil
 }
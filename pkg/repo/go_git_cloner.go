@@ -1,14 +1,25 @@
+//go:build gogit
 // +build gogit
 
 package repo
 
 import (
-	"gopkg.in/src-d/go-git.v4/storage/filesystem"
+	"errors"
+	"fmt"
+	"net"
 	"os"
+	"strings"
+	"syscall"
 
 	billy "gopkg.in/src-d/go-billy.v4"
 	git "gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/config"
+	"gopkg.in/src-d/go-git.v4/plumbing"
 	"gopkg.in/src-d/go-git.v4/plumbing/cache"
+	format "gopkg.in/src-d/go-git.v4/plumbing/format/config"
+	"gopkg.in/src-d/go-git.v4/plumbing/transport"
+	"gopkg.in/src-d/go-git.v4/storage/filesystem"
+	"gopkg.in/src-d/go-git.v4/storage/memory"
 )
 
 func init() {
@@ -25,16 +36,320 @@ type GitCloner struct {
 	Fs billy.Filesystem
 }
 
-// Clone clones a repository
+// Clone clones a repository, go-git cannot make partial
+// or sparse clones so a Filter or SparsePaths is an error.
+// Submodules are fetched one at a time
 func (gc *GitCloner) Clone(path string, opts *CloneOptions) error {
+	if opts.Filter != "" {
+		return fmt.Errorf("%w: filter %s", ErrUnsupportedCloneOption, opts.Filter)
+	}
+	if len(opts.SparsePaths) > 0 {
+		return fmt.Errorf("%w: sparse paths", ErrUnsupportedCloneOption)
+	}
+
+	cloneOpts := &git.CloneOptions{
+		URL:          opts.URL,
+		Auth:         opts.Auth,
+		Depth:        opts.Depth,
+		SingleBranch: opts.SingleBranch,
+	}
+	if opts.Progress != nil {
+		cloneOpts.Progress = newProgressWriter(opts.Progress)
+	}
+	// go-git assumes the default branch is master when cloning a
+	// single branch, so the branch is looked up on the remote
+	if opts.Reference != "" || opts.SingleBranch {
+		ref, err := remoteReference(opts.URL, opts.Auth, opts.Reference)
+		if err != nil {
+			return cloneError(err)
+		}
+		cloneOpts.ReferenceName = ref
+	}
+
 	repoFs, _ := gc.Fs.Chroot(path)
 	dot, _ := repoFs.Chroot(".git")
 	storer := filesystem.NewStorage(dot, cache.NewObjectLRU(cache.DefaultMaxSize))
 
-	_, err := git.Clone(storer, repoFs, &git.CloneOptions{
-		URL:      opts.URL,
-		Progress: os.Stdout,
-		Auth:     opts.Auth,
+	r, err := git.Clone(storer, repoFs, cloneOpts)
+	if err != nil {
+		return cloneError(err)
+	}
+	if opts.Submodules {
+		if err := updateSubmodules(r, opts); err != nil {
+			return fmt.Errorf("%w: %s", ErrCloneRepo, err)
+		}
+	}
+	if err := gc.Configure(path, opts.GitConfig); err != nil {
+		return err
+	}
+	if opts.LFS {
+		return fetchLFS(gc.Fs, path, opts.Auth)
+	}
+	return nil
+}
+
+// cloneError returns the error for why go-git failed to clone. Errors
+// from ssh and http lose their type, so their messages are classified
+func cloneError(err error) error {
+	var netErr net.Error
+	switch {
+	case errors.Is(err, ErrCloneRepo):
+		return err
+	case err == git.ErrRepositoryAlreadyExists:
+		return ErrRepoAlreadyExists
+	case err == transport.ErrAuthenticationRequired || err == transport.ErrAuthorizationFailed:
+		return fmt.Errorf("%w: %s", ErrAuthFailed, err)
+	case err == transport.ErrRepositoryNotFound:
+		return fmt.Errorf("%w: %s", ErrRepoNotFound, err)
+	case errors.Is(err, syscall.ENOSPC):
+		return fmt.Errorf("%w: %s", ErrDiskFull, err)
+	case errors.Is(err, os.ErrPermission):
+		return fmt.Errorf("%w: %s", ErrPermissionDenied, err)
+	case errors.As(err, &netErr):
+		return fmt.Errorf("%w: %s", ErrHostUnreachable, err)
+	}
+	return classifyOutput([]string{err.Error()}, err.Error())
+}
+
+// Configure sets the entries in the local git config of the repository at path
+func (gc *GitCloner) Configure(path string, config map[string]string) error {
+	if len(config) == 0 {
+		return nil
+	}
+	r, err := openRepo(gc.Fs, path)
+	if err != nil {
+		return err
+	}
+	cfg, err := r.Config()
+	if err != nil {
+		return err
+	}
+	for _, key := range SortedKeys(config) {
+		if err := setRawOption(cfg.Raw, key, config[key]); err != nil {
+			return err
+		}
+	}
+	if err := r.Storer.SetConfig(cfg); err != nil {
+		return fmt.Errorf("%w: %s", ErrConfigRepo, err)
+	}
+	return nil
+}
+
+// setRawOption sets the option for a key such as user.email, or with
+// a subsection such as branch.main.remote
+func setRawOption(raw *format.Config, key, value string) error {
+	first := strings.Index(key, ".")
+	last := strings.LastIndex(key, ".")
+	if first <= 0 || last == len(key)-1 {
+		return fmt.Errorf("%w: invalid key %s", ErrConfigRepo, key)
+	}
+
+	section := raw.Section(key[:first])
+	if first == last {
+		section.SetOption(key[last+1:], value)
+	} else {
+		section.Subsection(key[first+1:last]).SetOption(key[last+1:], value)
+	}
+	return nil
+}
+
+// updateSubmodules clones the submodules of the repo down to the
+// SubmoduleDepth levels of nesting. go-git recurses one level past
+// the RecurseSubmodules it is given, so it cannot be set on the clone
+func updateSubmodules(r *git.Repository, opts *CloneOptions) error {
+	wt, err := r.Worktree()
+	if err != nil {
+		return err
+	}
+	subs, err := wt.Submodules()
+	if err != nil {
+		return err
+	}
+
+	recurse := git.DefaultSubmoduleRecursionDepth
+	if opts.SubmoduleDepth > 0 {
+		recurse = git.SubmoduleRescursivity(opts.SubmoduleDepth - 1)
+	}
+	return subs.Update(&git.SubmoduleUpdateOptions{
+		Init:              true,
+		RecurseSubmodules: recurse,
+		Auth:              opts.Auth,
+	})
+}
+
+// remoteReference finds the branch or tag with the given name on the
+// remote, as go-git needs the full name of the reference to clone.
+// The default branch is found when name is empty
+func remoteReference(url string, auth AuthMethod, name string) (plumbing.ReferenceName, error) {
+	if strings.HasPrefix(name, "refs/") {
+		return plumbing.ReferenceName(name), nil
+	}
+
+	r, err := git.Init(memory.NewStorage(), nil)
+	if err != nil {
+		return "", err
+	}
+	remote, err := r.CreateRemote(&config.RemoteConfig{
+		Name: git.DefaultRemoteName,
+		URLs: []string{url},
 	})
+	if err != nil {
+		return "", err
+	}
+	refs, err := remote.List(&git.ListOptions{
+		Auth: auth,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	if name == "" {
+		return defaultBranch(refs)
+	}
+
+	for _, candidate := range []plumbing.ReferenceName{
+		plumbing.NewBranchReferenceName(name),
+		plumbing.NewTagReferenceName(name),
+	} {
+		for _, ref := range refs {
+			if ref.Name() == candidate {
+				return candidate, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("%w: no branch or tag named %s", ErrCloneRepo, name)
+}
+
+// defaultBranch finds the branch HEAD points to in the remote's refs
+func defaultBranch(refs []*plumbing.Reference) (plumbing.ReferenceName, error) {
+	var head *plumbing.Reference
+	for _, ref := range refs {
+		if ref.Name() == plumbing.HEAD {
+			head = ref
+		}
+	}
+	if head == nil {
+		return "", fmt.Errorf("%w: remote has no HEAD", ErrCloneRepo)
+	}
+	if head.Type() == plumbing.SymbolicReference {
+		return head.Target(), nil
+	}
+
+	// without the symref HEAD is the branch at the same commit
+	var found plumbing.ReferenceName
+	for _, ref := range refs {
+		if ref.Name().IsBranch() && ref.Hash() == head.Hash() {
+			if found == "" || ref.Name() == plumbing.Master {
+				found = ref.Name()
+			}
+		}
+	}
+	if found == "" {
+		return "", fmt.Errorf("%w: remote HEAD is not a branch", ErrCloneRepo)
+	}
+	return found, nil
+}
+
+// Pull fast-forwards the current branch of the repository at path
+func (gc *GitCloner) Pull(path string, opts *PullOptions) error {
+	r, err := openRepo(gc.Fs, path)
+	if err != nil {
+		return err
+	}
+	wt, err := r.Worktree()
+	if err != nil {
+		return err
+	}
+
+	err = retryPackedRefs(func() error {
+		return wt.Pull(&git.PullOptions{
+			RemoteName: "origin",
+			Auth:       opts.Auth,
+		})
+	})
+	if err == nil || err == git.NoErrAlreadyUpToDate {
+		return nil
+	}
+	// go-git only fast-forwards, its errors for diverged
+	// branches are not exported so every failure is wrapped
+	return fmt.Errorf("%w: %s", ErrPullRepo, err)
+}
+
+// Sync fetches the repository at path and fast-forwards its current
+// branch when it is behind its upstream and has no local changes
+func (gc *GitCloner) Sync(path string, opts *SyncOptions) (*SyncResult, error) {
+	r, err := openRepo(gc.Fs, path)
+	if err != nil {
+		return nil, err
+	}
+
+	fetchOpts := &git.FetchOptions{
+		RemoteName: "origin",
+		Auth:       opts.Auth,
+	}
+	err = retryPackedRefs(func() error {
+		return r.Fetch(fetchOpts)
+	})
+	if err != nil && err != git.NoErrAlreadyUpToDate {
+		return nil, fmt.Errorf("%w: %s", ErrFetchRepo, err)
+	}
+
+	res := &SyncResult{}
+	head, err := r.Head()
+	if err != nil {
+		return nil, err
+	}
+	if !head.Name().IsBranch() {
+		return res, nil
+	}
+	res.Branch = head.Name().Short()
+
+	upstream, err := upstreamRef(r, res.Branch)
+	if err != nil {
+		return nil, err
+	} else if upstream == nil {
+		res.NoUpstream = true
+		return res, nil
+	}
+
+	wt, err := r.Worktree()
+	if err != nil {
+		return nil, err
+	}
+	status, err := wt.Status()
+	if err != nil {
+		return nil, err
+	}
+	skipped, err := sparseSkipped(r)
+	if err != nil {
+		return nil, err
+	}
+	res.Dirty, _ = worktreeChanges(status, skipped)
+
+	if res.Ahead, res.Behind, err = aheadBehind(r, head.Hash(), upstream.Hash()); err != nil {
+		return nil, err
+	}
+
+	if res.CanFastForward() {
+		err := wt.Reset(&git.ResetOptions{
+			Commit: upstream.Hash(),
+			Mode:   git.MergeReset,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrPullRepo, err)
+		}
+		res.Updated = true
+	}
+	return res, nil
+}
+
+// retryPackedRefs runs fetch again when it fails because go-git could
+// not update a ref the first time it is moved out of packed-refs, such
+// as in repos cloned by the git binary
+func retryPackedRefs(fetch func() error) error {
+	err := fetch()
+	if err != nil && strings.Contains(err.Error(), "reference has changed concurrently") {
+		err = fetch()
+	}
 	return err
 }
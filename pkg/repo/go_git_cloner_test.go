@@ -0,0 +1,171 @@
+//go:build gogit
+// +build gogit
+
+package repo
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/src-d/go-billy.v4/memfs"
+	"gopkg.in/src-d/go-billy.v4/osfs"
+	git "gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/config"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+	"gopkg.in/src-d/go-git.v4/plumbing/cache"
+	"gopkg.in/src-d/go-git.v4/plumbing/transport"
+	"gopkg.in/src-d/go-git.v4/storage/filesystem"
+)
+
+func TestDefaultBranch(t *testing.T) {
+	main := plumbing.NewHashReference(plumbing.NewBranchReferenceName("main"), plumbing.NewHash("1111111111111111111111111111111111111111"))
+	feature := plumbing.NewHashReference(plumbing.NewBranchReferenceName("feature"), plumbing.NewHash("2222222222222222222222222222222222222222"))
+
+	branch, err := defaultBranch([]*plumbing.Reference{
+		plumbing.NewSymbolicReference(plumbing.HEAD, main.Name()),
+		main,
+		feature,
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, main.Name(), branch, "A symbolic HEAD should be followed")
+
+	branch, err = defaultBranch([]*plumbing.Reference{
+		plumbing.NewHashReference(plumbing.HEAD, feature.Hash()),
+		main,
+		feature,
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, feature.Name(), branch, "The branch at the HEAD commit should be found")
+
+	_, err = defaultBranch([]*plumbing.Reference{main})
+	assert.NotNil(t, err, "A remote without HEAD has no default branch")
+}
+
+func TestGitClonerConfigure(t *testing.T) {
+	fs := memfs.New()
+	repoFs, _ := fs.Chroot("/repos/hermes")
+	dot, _ := repoFs.Chroot(".git")
+	r, err := git.Init(filesystem.NewStorage(dot, cache.NewObjectLRU(cache.DefaultMaxSize)), repoFs)
+	assert.Nil(t, err)
+	_, err = r.CreateRemote(&config.RemoteConfig{Name: "origin", URLs: []string{"https://github.com/TheHipbot/hermes"}})
+	assert.Nil(t, err)
+
+	gc := &GitCloner{Fs: fs}
+	assert.Nil(t, gc.Configure("/repos/hermes", map[string]string{
+		"user.email":                    "jeremy@corp.com",
+		"commit.gpgsign":                "true",
+		"url.git@github.com:.insteadOf": "https://github.com/",
+	}))
+
+	r, _ = openRepo(fs, "/repos/hermes")
+	cfg, err := r.Config()
+	assert.Nil(t, err)
+	assert.Equal(t, "jeremy@corp.com", cfg.Raw.Section("user").Option("email"))
+	assert.Equal(t, "true", cfg.Raw.Section("commit").Option("gpgsign"))
+	assert.Equal(t, "https://github.com/", cfg.Raw.Section("url").Subsection("git@github.com:").Option("insteadOf"))
+	assert.Contains(t, cfg.Remotes, "origin", "Existing config should be kept")
+
+	err = gc.Configure("/repos/hermes", map[string]string{"email": "jeremy@corp.com"})
+	assert.True(t, errors.Is(err, ErrConfigRepo), "A key without a section should error")
+}
+
+func TestGitClonerTokenAuth(t *testing.T) {
+	dir, err := ioutil.TempDir("", "hermes-auth")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	assert.Nil(t, initBareRepo(dir, "hermes"))
+	server, err := newAuthGitServer(dir, testToken)
+	assert.Nil(t, err)
+	defer server.Close()
+
+	url := server.URL + "/hermes.git"
+	gc := &GitCloner{Fs: osfs.New(dir)}
+	err = gc.Clone("unauthenticated", &CloneOptions{URL: url})
+	assert.True(t, errors.Is(err, ErrAuthFailed), "The clone should need the token")
+	assert.Nil(t, gc.Clone("clone", &CloneOptions{
+		URL:  url,
+		Auth: newHTTPAuth("oauth2", testToken),
+	}))
+
+	config, err := ioutil.ReadFile(filepath.Join(dir, "clone", ".git", "config"))
+	assert.Nil(t, err)
+	assert.Contains(t, string(config), url, "origin should be the URL")
+	assert.NotContains(t, string(config), testToken, "The token should not be written to the git config")
+}
+
+func TestGitClonerSync(t *testing.T) {
+	runSyncCases(t, func(dir string) (Cloner, string) {
+		return &GitCloner{Fs: osfs.New(dir)}, "clone"
+	})
+}
+
+func TestCloneError(t *testing.T) {
+	assert.True(t, errors.Is(cloneError(transport.ErrAuthenticationRequired), ErrAuthFailed))
+	assert.True(t, errors.Is(cloneError(transport.ErrRepositoryNotFound), ErrRepoNotFound))
+	assert.Equal(t, ErrRepoAlreadyExists, cloneError(git.ErrRepositoryAlreadyExists))
+	assert.True(t, errors.Is(cloneError(&os.PathError{Op: "write", Path: "README.md", Err: syscall.ENOSPC}), ErrDiskFull))
+	assert.True(t, errors.Is(cloneError(&os.PathError{Op: "mkdir", Path: "/repos", Err: syscall.EACCES}), ErrPermissionDenied))
+	assert.True(t, errors.Is(cloneError(&net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}), ErrHostUnreachable))
+	assert.True(t, errors.Is(cloneError(errors.New("ssh: handshake failed: "+ErrNoSSHKey.Error())), ErrAuthFailed),
+		"Errors from ssh should be classified by their message")
+
+	err := cloneError(fmt.Errorf("%w: no branch or tag named v2", ErrCloneRepo))
+	assert.Equal(t, "error cloning repo: no branch or tag named v2", err.Error(), "Clone errors should not be wrapped again")
+}
+
+func (suite *GitRepositorySuite) TestCloneRepo() {
+	pathToClone := fmt.Sprintf("%s%s", testReposPath, testRepoName)
+	repoURL, err := url.Parse("https://github.com/TheHipbot/hermes")
+	suite.Nil(err, "Test URL could not be parsed")
+
+	repo := NewGitRepository(testRepoName, repoURL.String())
+	repo.Fs = appFs
+	repo.Cloner = &GitCloner{
+		Fs: appFs,
+	}
+
+	suite.Nil(repo.Clone(pathToClone), "Error cloning repo")
+
+	// is there a directory in the memfs for the cloned repo
+	fileInfo, err := appFs.Stat(pathToClone)
+	suite.Nil(err, fmt.Sprintf("Error getting directory %s stat", pathToClone))
+	suite.True(fileInfo.IsDir(), "Repo path should be a directory")
+
+	// is .git a directory
+	gitPath := fmt.Sprintf("%s/.git", pathToClone)
+	fileInfo, err = appFs.Stat(gitPath)
+	suite.Nil(err, fmt.Sprintf("Error getting directory %s stat", gitPath))
+	suite.True(fileInfo.IsDir(), fmt.Sprintf("%s path should be a directory", gitPath))
+
+	// is there a README and main.go
+	fileInfo, err = appFs.Stat(fmt.Sprintf("%s/README.md", pathToClone))
+	suite.Nil(err, "Error getting README stat")
+	suite.True(fileInfo.Mode().IsRegular(), "README is missing")
+
+	fileInfo, err = appFs.Stat(fmt.Sprintf("%s/main.go", pathToClone))
+	suite.Nil(err, "Error getting main stat")
+	suite.True(fileInfo.Mode().IsRegular(), "main.go is missing")
+}
+
+func (suite *GitRepositorySuite) TestCloneExistingRepo() {
+	pathToClone := fmt.Sprintf("%s%s", testReposPath, testRepoName)
+	repoURL, err := url.Parse("https://github.com/TheHipbot/hermes")
+	suite.Nil(err, "Test URL could not be parsed")
+
+	defaultCloner = &GitCloner{
+		Fs: appFs,
+	}
+	repo := NewGitRepository(testRepoName, repoURL.String())
+	repo.Fs = appFs
+
+	suite.Nil(repo.Clone(pathToClone), "Error cloning repo")
+	suite.Equal(repo.Clone(pathToClone), git.ErrRepositoryAlreadyExists, "Should throw ErrRepositoryAlreadyExists error")
+}
@@ -0,0 +1,66 @@
+package repo
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	billy "gopkg.in/src-d/go-billy.v4"
+)
+
+var (
+	// lfsWarnings is where a missing git-lfs is reported
+	lfsWarnings io.Writer = os.Stderr
+
+	// lookPath finds the binaries run by the cloners
+	lookPath = exec.LookPath
+)
+
+// usesLFS reports whether the .gitattributes file in the root
+// of the repo at path tracks any files with LFS
+func usesLFS(fs billy.Filesystem, path string) bool {
+	f, err := fs.Open(filepath.Join(path, ".gitattributes"))
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		for _, attr := range fields[1:] {
+			if attr == "filter=lfs" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// fetchLFS downloads and checks out the LFS files of the repo at path
+// with the auth of the clone. The clone itself succeeded, so a missing
+// git-lfs is only a warning
+func fetchLFS(fs billy.Filesystem, path string, auth AuthMethod) error {
+	if !usesLFS(fs, path) {
+		return nil
+	}
+	if _, err := lookPath("git-lfs"); err != nil {
+		fmt.Fprintf(lfsWarnings, "warning: %s uses Git LFS but git-lfs is not installed, LFS files are left as pointers\n", path)
+		return nil
+	}
+
+	args, env := gitAuth(auth)
+	cmd := exec.Command("git", append(args, "-C", path, "lfs", "pull")...)
+	cmd.Env = append(os.Environ(), env...)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%w: git lfs pull: %s", ErrCloneRepo, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package repo
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	billy "gopkg.in/src-d/go-billy.v4"
+	"gopkg.in/src-d/go-billy.v4/memfs"
+	"gopkg.in/src-d/go-billy.v4/util"
+)
+
+type LFSSuite struct {
+	suite.Suite
+	fs       billy.Filesystem
+	warnings *bytes.Buffer
+}
+
+func (s *LFSSuite) SetupTest() {
+	s.fs = memfs.New()
+	s.warnings = &bytes.Buffer{}
+	lfsWarnings = s.warnings
+}
+
+func (s *LFSSuite) TearDownTest() {
+	lfsWarnings = os.Stderr
+	lookPath = exec.LookPath
+}
+
+func (s *LFSSuite) TestUsesLFS() {
+	s.False(usesLFS(s.fs, "/repos/hermes"), "A repo without .gitattributes does not use LFS")
+
+	util.WriteFile(s.fs, "/repos/hermes/.gitattributes", []byte("*.go text eol=lf\n\n# *.bin filter=lfs\n"), 0644)
+	s.False(usesLFS(s.fs, "/repos/hermes"), "Commented attributes should be ignored")
+
+	util.WriteFile(s.fs, "/repos/hermes/.gitattributes", []byte("*.go text eol=lf\n*.psd filter=lfs diff=lfs merge=lfs -text\n"), 0644)
+	s.True(usesLFS(s.fs, "/repos/hermes"))
+}
+
+func (s *LFSSuite) TestFetchLFSWithoutGitLFS() {
+	lookPath = func(file string) (string, error) {
+		return "", errors.New("not found")
+	}
+
+	s.Nil(fetchLFS(s.fs, "/repos/hermes", nil))
+	s.Empty(s.warnings.String(), "A repo which does not use LFS should not warn")
+
+	util.WriteFile(s.fs, "/repos/hermes/.gitattributes", []byte("*.psd filter=lfs diff=lfs merge=lfs -text\n"), 0644)
+	s.Nil(fetchLFS(s.fs, "/repos/hermes", nil), "A missing git-lfs should not fail the clone")
+	s.Contains(s.warnings.String(), "/repos/hermes uses Git LFS but git-lfs is not installed")
+}
+
+func TestLFSSuite(t *testing.T) {
+	suite.Run(t, new(LFSSuite))
+}
@@ -0,0 +1,112 @@
+package repo
+
+import (
+	"bytes"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	// percentProgress matches progress git knows the total of, such as
+	// Receiving objects:  45% (450/1000), 1.20 MiB | 2.00 MiB/s
+	percentProgress = regexp.MustCompile(`^([A-Z][A-Za-z ]+):\s+(\d+)% \((\d+)/(\d+)\)(?:, ([\d.]+) (bytes|KiB|MiB|GiB))?`)
+
+	// countProgress matches progress without a total, such as
+	// Enumerating objects: 1234, done.
+	countProgress = regexp.MustCompile(`^([A-Z][A-Za-z ]+): (\d+)(?:, done\.)?$`)
+
+	// ignoredOutput is output from git which is neither progress nor
+	// worth showing, such as Cloning into 'hermes'...
+	ignoredOutput = regexp.MustCompile(`^(Cloning into |Total \d+)`)
+
+	byteUnits = map[string]float64{
+		"bytes": 1,
+		"KiB":   1 << 10,
+		"MiB":   1 << 20,
+		"GiB":   1 << 30,
+	}
+)
+
+// parseProgress parses a line of git's progress output,
+// it returns false when the line is not progress
+func parseProgress(line string) (ProgressEvent, bool) {
+	done := strings.HasSuffix(line, ", done.")
+	if m := percentProgress.FindStringSubmatch(line); m != nil {
+		event := ProgressEvent{
+			Phase: m[1],
+			Done:  done,
+		}
+		event.Percent, _ = strconv.Atoi(m[2])
+		event.Objects, _ = strconv.Atoi(m[3])
+		event.Total, _ = strconv.Atoi(m[4])
+		if m[5] != "" {
+			size, _ := strconv.ParseFloat(m[5], 64)
+			event.Bytes = int64(size * byteUnits[m[6]])
+		}
+		return event, true
+	}
+	if m := countProgress.FindStringSubmatch(line); m != nil {
+		event := ProgressEvent{
+			Phase: m[1],
+			Done:  done,
+		}
+		event.Objects, _ = strconv.Atoi(m[2])
+		if done {
+			event.Percent = 100
+		}
+		return event, true
+	}
+	return ProgressEvent{}, false
+}
+
+// reportLine reports a line of git's output to the reporter as
+// progress, or as a message when it is not progress
+func reportLine(reporter ProgressReporter, line string) {
+	line = strings.TrimSpace(strings.TrimPrefix(line, "remote:"))
+	if reporter == nil || line == "" || ignoredOutput.MatchString(line) {
+		return
+	}
+	if event, ok := parseProgress(line); ok {
+		reporter.Progress(event)
+		return
+	}
+	reporter.Progress(ProgressEvent{Message: line})
+}
+
+// scanProgressLines splits git's output into lines, which end with \r
+// when git rewrites them to update progress, or else \n
+func scanProgressLines(data []byte, atEOF bool) (int, []byte, error) {
+	if i := bytes.IndexAny(data, "\r\n"); i >= 0 {
+		return i + 1, data[:i], nil
+	}
+	if atEOF && len(data) > 0 {
+		return len(data), data, nil
+	}
+	return 0, nil, nil
+}
+
+// progressWriter reports the output written to it by go-git
+// to the reporter, a line at a time
+type progressWriter struct {
+	reporter ProgressReporter
+	buf      []byte
+}
+
+func newProgressWriter(reporter ProgressReporter) *progressWriter {
+	return &progressWriter{
+		reporter: reporter,
+	}
+}
+
+func (w *progressWriter) Write(p []byte) (int, error) {
+	w.buf = append(w.buf, p...)
+	for {
+		advance, line, _ := scanProgressLines(w.buf, false)
+		if advance == 0 {
+			return len(p), nil
+		}
+		reportLine(w.reporter, string(line))
+		w.buf = w.buf[advance:]
+	}
+}
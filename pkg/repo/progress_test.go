@@ -0,0 +1,87 @@
+package repo
+
+import (
+	"bufio"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type ProgressSuite struct {
+	suite.Suite
+}
+
+// eventRecorder keeps the events reported to it
+type eventRecorder struct {
+	events []ProgressEvent
+}
+
+func (r *eventRecorder) Progress(event ProgressEvent) {
+	r.events = append(r.events, event)
+}
+
+func (s *ProgressSuite) TestParseProgress() {
+	event, ok := parseProgress("Receiving objects:  45% (450/1000), 1.50 MiB | 2.00 MiB/s")
+	s.True(ok)
+	s.Equal(ProgressEvent{
+		Phase:   "Receiving objects",
+		Percent: 45,
+		Objects: 450,
+		Total:   1000,
+		Bytes:   1572864,
+	}, event)
+
+	event, ok = parseProgress("Resolving deltas: 100% (12/12), done.")
+	s.True(ok)
+	s.Equal(ProgressEvent{Phase: "Resolving deltas", Percent: 100, Objects: 12, Total: 12, Done: true}, event)
+
+	event, ok = parseProgress("Enumerating objects: 1234, done.")
+	s.True(ok)
+	s.Equal(ProgressEvent{Phase: "Enumerating objects", Percent: 100, Objects: 1234, Done: true}, event)
+
+	event, ok = parseProgress("Enumerating objects: 12")
+	s.True(ok)
+	s.Equal(ProgressEvent{Phase: "Enumerating objects", Objects: 12}, event)
+
+	_, ok = parseProgress("warning: You appear to have cloned an empty repository.")
+	s.False(ok)
+}
+
+func (s *ProgressSuite) TestProgressWriter() {
+	recorder := &eventRecorder{}
+	w := newProgressWriter(recorder)
+	w.Write([]byte("Enumerating objects: 5, done.\nCounting objects:  50% (1/2)\rCounting obj"))
+	w.Write([]byte("ects: 100% (2/2), done.\nTotal 5 (delta 0), reused 0 (delta 0)\n"))
+	w.Write([]byte("GitLab: this project is archived\n"))
+
+	s.Equal([]ProgressEvent{
+		{Phase: "Enumerating objects", Percent: 100, Objects: 5, Done: true},
+		{Phase: "Counting objects", Percent: 50, Objects: 1, Total: 2},
+		{Phase: "Counting objects", Percent: 100, Objects: 2, Total: 2, Done: true},
+		{Message: "GitLab: this project is archived"},
+	}, recorder.events, "Lines rewritten with \\r should be separate events")
+
+	s.NotPanics(func() {
+		newProgressWriter(nil).Write([]byte("Counting objects:  50% (1/2)\r"))
+	}, "Progress should be dropped without a reporter")
+}
+
+func (s *ProgressSuite) TestScanProgressLines() {
+	scanner := bufio.NewScanner(strings.NewReader("Cloning into 'hermes'...\nremote: Counting objects:  50% (1/2)   \rremote: Counting objects: 100% (2/2), done.   \nReceiving objects: 100% (2/2)"))
+	scanner.Split(scanProgressLines)
+	recorder := &eventRecorder{}
+	for scanner.Scan() {
+		reportLine(recorder, scanner.Text())
+	}
+
+	s.Equal([]ProgressEvent{
+		{Phase: "Counting objects", Percent: 50, Objects: 1, Total: 2},
+		{Phase: "Counting objects", Percent: 100, Objects: 2, Total: 2, Done: true},
+		{Phase: "Receiving objects", Percent: 100, Objects: 2, Total: 2},
+	}, recorder.events, "The remote prefix should be removed and Cloning into ignored")
+}
+
+func TestProgressSuite(t *testing.T) {
+	suite.Run(t, new(ProgressSuite))
+}
@@ -0,0 +1,410 @@
+package repo
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/TheHipbot/hermes/pkg/prompt"
+	"github.com/kevinburke/ssh_config"
+	homedir "github.com/mitchellh/go-homedir"
+	"golang.org/x/crypto/ed25519"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+	"golang.org/x/crypto/ssh/knownhosts"
+	"gopkg.in/src-d/go-git.v4/plumbing/transport"
+)
+
+var (
+	// sshConfigGet reads the setting for a host from ssh_config
+	sshConfigGet = ssh_config.Get
+
+	// sshWarnings is where keys which cannot be used are reported
+	sshWarnings io.Writer = os.Stderr
+
+	// defaultIdentityFiles are tried after the IdentityFile set in ssh_config
+	defaultIdentityFiles = []string{
+		"~/.ssh/id_ed25519",
+		"~/.ssh/id_ecdsa",
+		"~/.ssh/id_rsa",
+		"~/.ssh/id_dsa",
+	}
+
+	// loadedKeys are the keys read by path, so the passphrase of
+	// a key is asked for once even when cloning in parallel
+	loadedKeys   = map[string]ssh.Signer{}
+	loadedKeysMu sync.Mutex
+)
+
+// sshConfig is the ssh_config go-git uses to find the address to connect
+// to. go-git only reads the Port of hosts with a HostName, so the host
+// itself is given as the HostName of hosts with only a Port set
+type sshConfig struct{}
+
+func (sshConfig) Get(host, key string) string {
+	value := sshConfigGet(host, key)
+	if strings.EqualFold(key, "Hostname") && value == "" {
+		if port := sshConfigGet(host, "Port"); port != "" && port != "22" {
+			return host
+		}
+	}
+	return value
+}
+
+// SSHURL returns the ssh URL of the named repo, such as
+// github.com/TheHipbot/hermes, using the User and Port
+// set for its host in ssh_config
+func SSHURL(name string) string {
+	parts := strings.SplitN(name, "/", 2)
+	host := parts[0]
+	user := sshConfigGet(host, "User")
+	if user == "" {
+		user = "git"
+	}
+	if port := sshConfigGet(host, "Port"); port != "" && port != "22" {
+		host = net.JoinHostPort(host, port)
+	}
+	if len(parts) < 2 {
+		return fmt.Sprintf("ssh://%s@%s", user, host)
+	}
+	return fmt.Sprintf("ssh://%s@%s/%s", user, host, parts[1])
+}
+
+// sshAuthMethod authenticates go-git ssh connections. Its client config
+// is built when go-git connects, so keys are only read and passphrases
+// only asked for when the go-git cloner is used
+type sshAuthMethod struct {
+	user     string
+	host     string
+	port     int
+	prompter prompt.Factory
+
+	mu sync.Mutex
+	// agentConn is the connection to ssh-agent, kept open
+	// until the operation using the auth is done
+	agentConn net.Conn
+	// promptErr is why a passphrase could not be asked for, which
+	// the ssh handshake would otherwise hide
+	promptErr error
+}
+
+// newSSHAuth creates the auth for the ssh URL, its user is the one in the
+// URL, or else the User set in ssh_config, or else git
+func newSSHAuth(url string, prompter prompt.Factory) (*sshAuthMethod, error) {
+	ep, err := transport.NewEndpoint(url)
+	if err != nil {
+		return nil, err
+	}
+	if prompter == nil {
+		prompter = &prompt.NonInteractivePrompter{}
+	}
+
+	user := ep.User
+	if user == "" {
+		user = sshConfigGet(ep.Host, "User")
+	}
+	if user == "" {
+		user = "git"
+	}
+	return &sshAuthMethod{
+		user:     user,
+		host:     ep.Host,
+		port:     ep.Port,
+		prompter: prompter,
+	}, nil
+}
+
+// Name is the name of the auth method
+func (a *sshAuthMethod) Name() string {
+	return "ssh-hermes"
+}
+
+func (a *sshAuthMethod) String() string {
+	return fmt.Sprintf("user: %s, name: %s", a.user, a.Name())
+}
+
+// ClientConfig authenticates with the keys in ssh-agent and then the key
+// files, and verifies the host key against known_hosts
+func (a *sshAuthMethod) ClientConfig() (*ssh.ClientConfig, error) {
+	hostKeyCallback, algorithms, err := knownHosts(a.host, a.address())
+	if err != nil {
+		return nil, err
+	}
+	return &ssh.ClientConfig{
+		User:              a.user,
+		Auth:              []ssh.AuthMethod{ssh.PublicKeysCallback(a.signers)},
+		HostKeyCallback:   hostKeyCallback,
+		HostKeyAlgorithms: algorithms,
+	}, nil
+}
+
+// address is the host and port go-git connects to, the HostName and Port
+// in ssh_config are used when set
+func (a *sshAuthMethod) address() string {
+	host, port := a.host, a.port
+	if hostname := (sshConfig{}).Get(a.host, "Hostname"); hostname != "" {
+		if p, err := strconv.Atoi(sshConfigGet(a.host, "Port")); err == nil {
+			port = p
+		}
+		host = hostname
+	}
+	if port <= 0 {
+		port = 22
+	}
+	return net.JoinHostPort(host, strconv.Itoa(port))
+}
+
+// identityFiles are the paths of the keys to try for the host
+func (a *sshAuthMethod) identityFiles() []string {
+	paths := []string{}
+	seen := map[string]bool{}
+	for _, file := range append([]string{sshConfigGet(a.host, "IdentityFile")}, defaultIdentityFiles...) {
+		if file == "" {
+			continue
+		}
+		path, err := homedir.Expand(file)
+		if err != nil || seen[path] {
+			continue
+		}
+		seen[path] = true
+		paths = append(paths, path)
+	}
+	return paths
+}
+
+// finish closes the connection to ssh-agent once the operation using the
+// auth is done, and returns why a passphrase could not be asked for in
+// place of the error the operation failed with
+func (a *sshAuthMethod) finish(err error) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.agentConn != nil {
+		a.agentConn.Close()
+		a.agentConn = nil
+	}
+	if err != nil && a.promptErr != nil {
+		return a.promptErr
+	}
+	return err
+}
+
+// signers returns the keys in ssh-agent followed by the key files,
+// keys which cannot be read are skipped with a warning
+func (a *sshAuthMethod) signers() ([]ssh.Signer, error) {
+	signers := a.agentSigners()
+	for _, path := range a.identityFiles() {
+		signer, err := a.key(path)
+		if err != nil {
+			fmt.Fprintf(sshWarnings, "warning: %s\n", err)
+			continue
+		}
+		if signer != nil && !hasKey(signers, signer.PublicKey()) {
+			signers = append(signers, signer)
+		}
+	}
+	if len(signers) == 0 {
+		return nil, ErrNoSSHKey
+	}
+	return signers, nil
+}
+
+// agentSigners returns the keys in the ssh-agent at SSH_AUTH_SOCK, the
+// connection to it is shared by every handshake until finish is called
+func (a *sshAuthMethod) agentSigners() []ssh.Signer {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.agentConn == nil {
+		sock := os.Getenv("SSH_AUTH_SOCK")
+		if sock == "" {
+			return nil
+		}
+		conn, err := net.Dial("unix", sock)
+		if err != nil {
+			return nil
+		}
+		a.agentConn = conn
+	}
+	signers, err := agent.NewClient(a.agentConn).Signers()
+	if err != nil {
+		return nil
+	}
+	return signers
+}
+
+// key reads the private key at path, or returns nil if there is none.
+// Keys encrypted with a passphrase are decrypted after asking for it,
+// when the public key is in the key or in path.pub that is only once
+// the server has accepted it
+func (a *sshAuthMethod) key(path string) (ssh.Signer, error) {
+	loadedKeysMu.Lock()
+	signer, ok := loadedKeys[path]
+	loadedKeysMu.Unlock()
+	if ok {
+		return signer, nil
+	}
+
+	pemBytes, err := readFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	signer, err = ssh.ParsePrivateKey(pemBytes)
+	if err == nil {
+		loadedKeysMu.Lock()
+		loadedKeys[path] = signer
+		loadedKeysMu.Unlock()
+		return signer, nil
+	}
+	missing, ok := err.(*ssh.PassphraseMissingError)
+	if !ok {
+		return nil, fmt.Errorf("could not read ssh key %s, add it to ssh-agent to use it: %s", path, err)
+	}
+
+	// keys in the OpenSSH format hold their public key unencrypted
+	pub := missing.PublicKey
+	if pub == nil {
+		if pubBytes, err := readFile(path + ".pub"); err == nil {
+			pub, _, _, _, _ = ssh.ParseAuthorizedKey(pubBytes)
+		}
+	}
+	if pub != nil {
+		return &passphraseSigner{
+			auth: a,
+			path: path,
+			pem:  pemBytes,
+			pub:  pub,
+		}, nil
+	}
+	return a.decryptKey(path, pemBytes)
+}
+
+// decryptKey asks for the passphrase of the encrypted key at path
+func (a *sshAuthMethod) decryptKey(path string, pemBytes []byte) (ssh.Signer, error) {
+	loadedKeysMu.Lock()
+	defer loadedKeysMu.Unlock()
+	if signer, ok := loadedKeys[path]; ok {
+		return signer, nil
+	}
+
+	passphrase, err := prompt.CreatePassphraseInputPrompt(a.prompter, path).Run()
+	if err != nil {
+		a.mu.Lock()
+		a.promptErr = err
+		a.mu.Unlock()
+		return nil, err
+	}
+	signer, err := ssh.ParsePrivateKeyWithPassphrase(pemBytes, []byte(passphrase))
+	if err != nil {
+		return nil, fmt.Errorf("could not decrypt ssh key %s: %s", path, err)
+	}
+	loadedKeys[path] = signer
+	return signer, nil
+}
+
+// passphraseSigner is an encrypted key which is
+// only decrypted when it is used to sign
+type passphraseSigner struct {
+	auth *sshAuthMethod
+	path string
+	pem  []byte
+	pub  ssh.PublicKey
+}
+
+func (s *passphraseSigner) PublicKey() ssh.PublicKey {
+	return s.pub
+}
+
+func (s *passphraseSigner) Sign(rand io.Reader, data []byte) (*ssh.Signature, error) {
+	signer, err := s.auth.decryptKey(s.path, s.pem)
+	if err != nil {
+		return nil, err
+	}
+	return signer.Sign(rand, data)
+}
+
+// hasKey reports whether one of the signers has the public key
+func hasKey(signers []ssh.Signer, key ssh.PublicKey) bool {
+	for _, s := range signers {
+		if bytes.Equal(s.PublicKey().Marshal(), key.Marshal()) {
+			return true
+		}
+	}
+	return false
+}
+
+// knownHosts returns the callback verifying host keys against the known_hosts
+// files set in ssh_config, along with the types of keys known for the address
+// so the server is asked for one of those
+func knownHosts(host, address string) (ssh.HostKeyCallback, []string, error) {
+	// knownhosts reads the files itself, so they are found on the os
+	files := []string{}
+	for _, key := range []string{"UserKnownHostsFile", "GlobalKnownHostsFile"} {
+		for _, file := range strings.Fields(sshConfigGet(host, key)) {
+			path, err := homedir.Expand(file)
+			if err != nil {
+				continue
+			}
+			if _, err := os.Stat(path); err == nil {
+				files = append(files, path)
+			}
+		}
+	}
+
+	callback, err := knownhosts.New(files...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	hostKeyCallback := func(hostname string, remote net.Addr, key ssh.PublicKey) error {
+		err := callback(hostname, remote, key)
+		if keyErr, ok := err.(*knownhosts.KeyError); ok {
+			if len(keyErr.Want) == 0 {
+				return fmt.Errorf("%w for %s, connect to it once with ssh to add it", ErrUnknownHostKey, hostname)
+			}
+			return fmt.Errorf("%w for %s, it may have changed or the connection is being intercepted", ErrHostKeyMismatch, hostname)
+		}
+		return err
+	}
+	return hostKeyCallback, knownKeyTypes(callback, address), nil
+}
+
+// knownKeyTypes returns the types of the keys known for the address, found
+// by checking a key which is never known and reading the keys wanted instead
+func knownKeyTypes(callback ssh.HostKeyCallback, address string) []string {
+	unknown, err := ssh.NewPublicKey(ed25519.PublicKey(make([]byte, ed25519.PublicKeySize)))
+	if err != nil {
+		return nil
+	}
+	remote := &net.TCPAddr{IP: net.IPv4zero}
+	keyErr, ok := callback(address, remote, unknown).(*knownhosts.KeyError)
+	if !ok {
+		return nil
+	}
+
+	types := []string{}
+	for _, k := range keyErr.Want {
+		types = append(types, k.Key.Type())
+	}
+	sort.Strings(types)
+	return types
+}
+
+// readFile reads the file at path from the app's filesystem
+func readFile(path string) ([]byte, error) {
+	f, err := appFs.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ioutil.ReadAll(f)
+}
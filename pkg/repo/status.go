@@ -0,0 +1,321 @@
+package repo
+
+import (
+	"container/heap"
+	"io/ioutil"
+	"strings"
+	"time"
+
+	billy "gopkg.in/src-d/go-billy.v4"
+	git "gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+	"gopkg.in/src-d/go-git.v4/plumbing/cache"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+	"gopkg.in/src-d/go-git.v4/storage/filesystem"
+)
+
+// LocalStatus describes the state of a clone without
+// contacting its remote
+type LocalStatus struct {
+	// Branch is empty when HEAD is detached
+	Branch     string
+	Dirty      bool
+	Untracked  int
+	NoUpstream bool
+	Ahead      int
+	Behind     int
+	Stashes    int
+	// LastCommit is zero when the branch has no commits
+	LastCommit time.Time
+	// UnpushedBranches counts every local branch, the current one
+	// included, which is ahead of its upstream or has none
+	UnpushedBranches int
+}
+
+// Unpushed reports whether the clone has commits which are not on
+// their upstream on any branch, including a branch which has no
+// upstream at all
+func (s *LocalStatus) Unpushed() bool {
+	return s.Ahead > 0 || (s.Branch != "" && s.NoUpstream) || s.UnpushedBranches > 0
+}
+
+// GetLocalStatus reads the status of the repository cloned at path on fs
+// using go-git, so it does not need the git binary
+func GetLocalStatus(fs billy.Filesystem, path string) (*LocalStatus, error) {
+	r, err := openRepo(fs, path)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &LocalStatus{}
+	head, err := r.Reference(plumbing.HEAD, false)
+	if err != nil {
+		return nil, err
+	}
+	if head.Type() == plumbing.SymbolicReference {
+		res.Branch = head.Target().Short()
+	}
+
+	wt, err := r.Worktree()
+	if err != nil {
+		return nil, err
+	}
+	status, err := wt.Status()
+	if err != nil {
+		return nil, err
+	}
+	skipped, err := sparseSkipped(r)
+	if err != nil {
+		return nil, err
+	}
+	res.Dirty, res.Untracked = worktreeChanges(status, skipped)
+
+	if res.Stashes, err = countStashes(fs, path); err != nil {
+		return nil, err
+	}
+	if res.UnpushedBranches, err = countUnpushedBranches(r); err != nil {
+		return nil, err
+	}
+
+	headHash, err := r.ResolveRevision(plumbing.Revision(plumbing.HEAD))
+	if err == plumbing.ErrReferenceNotFound {
+		// nothing has been committed yet
+		res.NoUpstream = true
+		return res, nil
+	} else if err != nil {
+		return nil, err
+	}
+	commit, err := r.CommitObject(*headHash)
+	if err != nil {
+		return nil, err
+	}
+	res.LastCommit = commit.Committer.When
+
+	if res.Branch == "" {
+		return res, nil
+	}
+	upstream, err := upstreamRef(r, res.Branch)
+	if err != nil {
+		return nil, err
+	} else if upstream == nil {
+		res.NoUpstream = true
+		return res, nil
+	}
+	res.Ahead, res.Behind, err = aheadBehind(r, *headHash, upstream.Hash())
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// countUnpushedBranches counts the local branches which have commits
+// their upstream does not, or which have no upstream
+func countUnpushedBranches(r *git.Repository) (int, error) {
+	branches, err := r.Branches()
+	if err != nil {
+		return 0, err
+	}
+	count := 0
+	err = branches.ForEach(func(b *plumbing.Reference) error {
+		upstream, err := upstreamRef(r, b.Name().Short())
+		if err != nil {
+			return err
+		} else if upstream == nil {
+			count++
+			return nil
+		}
+		ahead, _, err := aheadBehind(r, b.Hash(), upstream.Hash())
+		if err != nil {
+			return err
+		}
+		if ahead > 0 {
+			count++
+		}
+		return nil
+	})
+	return count, err
+}
+
+// openRepo opens the repository cloned at path on fs
+func openRepo(fs billy.Filesystem, path string) (*git.Repository, error) {
+	repoFs, err := fs.Chroot(path)
+	if err != nil {
+		return nil, err
+	}
+	dot, _ := repoFs.Chroot(".git")
+	storer := filesystem.NewStorage(dot, cache.NewObjectLRU(cache.DefaultMaxSize))
+
+	return git.Open(storer, repoFs)
+}
+
+// upstreamRef returns the remote branch which branch tracks,
+// or nil when it has none
+func upstreamRef(r *git.Repository, branch string) (*plumbing.Reference, error) {
+	cfg, err := r.Config()
+	if err != nil {
+		return nil, err
+	}
+	b, ok := cfg.Branches[branch]
+	if !ok || b.Remote == "" || b.Merge == "" {
+		return nil, nil
+	}
+	upstream, err := r.Reference(plumbing.NewRemoteReferenceName(b.Remote, b.Merge.Short()), true)
+	if err == plumbing.ErrReferenceNotFound {
+		return nil, nil
+	}
+	return upstream, err
+}
+
+// sparseSkipped returns the files left out of a sparse checkout,
+// which go-git reports as deleted
+func sparseSkipped(r *git.Repository) (map[string]bool, error) {
+	idx, err := r.Storer.Index()
+	if err != nil {
+		return nil, err
+	}
+	skipped := map[string]bool{}
+	for _, e := range idx.Entries {
+		if e.SkipWorktree {
+			skipped[e.Name] = true
+		}
+	}
+	return skipped, nil
+}
+
+// worktreeChanges reports whether any tracked files have changed
+// and counts the untracked files, skipped files are ignored
+func worktreeChanges(status git.Status, skipped map[string]bool) (bool, int) {
+	dirty, untracked := false, 0
+	for name, s := range status {
+		if skipped[name] {
+			continue
+		}
+		if s.Worktree == git.Untracked {
+			untracked++
+		} else if s.Staging != git.Unmodified || s.Worktree != git.Unmodified {
+			dirty = true
+		}
+	}
+	return dirty, untracked
+}
+
+// countStashes counts the entries in the stash reflog, which
+// go-git does not read
+func countStashes(fs billy.Filesystem, path string) (int, error) {
+	f, err := fs.Open(fs.Join(path, ".git", "logs", "refs", "stash"))
+	if err != nil {
+		if _, statErr := fs.Stat(fs.Join(path, ".git", "refs", "stash")); statErr == nil {
+			return 1, nil
+		}
+		return 0, nil
+	}
+	defer f.Close()
+
+	raw, err := ioutil.ReadAll(f)
+	if err != nil {
+		return 0, err
+	}
+	return len(strings.Split(strings.TrimSpace(string(raw)), "\n")), nil
+}
+
+const (
+	reachableFromLocal = 1 << iota
+	reachableFromUpstream
+	reachableFromBoth = reachableFromLocal | reachableFromUpstream
+)
+
+// aheadBehind counts the commits only reachable from local and those
+// only reachable from upstream. Both histories are walked together from
+// the newest commit down, like git does, so the walk stops once every
+// commit left is reachable from both rather than reading all of history
+func aheadBehind(r *git.Repository, local, upstream plumbing.Hash) (int, int, error) {
+	if local == upstream {
+		return 0, 0, nil
+	}
+
+	flags := map[plumbing.Hash]int{}
+	walked := map[plumbing.Hash]bool{}
+	// requeued commits were walked before being reached from the
+	// other side, so their ancestors need to be walked again
+	requeued := map[plumbing.Hash]bool{}
+	// counts are the commits walked by which sides they are reachable from
+	counts := map[int]int{}
+	queue := &commitQueue{}
+	mark := func(h plumbing.Hash, flag int) error {
+		if flags[h]|flag == flags[h] {
+			return nil
+		}
+		prev := flags[h]
+		flags[h] |= flag
+		if walked[h] {
+			// a commit older than one of its descendants was
+			// counted before the other side reached it
+			walked[h] = false
+			requeued[h] = true
+			counts[prev]--
+		} else if prev != 0 {
+			return nil
+		}
+		c, err := r.CommitObject(h)
+		if err == plumbing.ErrObjectNotFound {
+			// missing from a shallow clone
+			return nil
+		} else if err != nil {
+			return err
+		}
+		heap.Push(queue, c)
+		return nil
+	}
+	if err := mark(local, reachableFromLocal); err != nil {
+		return 0, 0, err
+	}
+	if err := mark(upstream, reachableFromUpstream); err != nil {
+		return 0, 0, err
+	}
+
+	interesting := func() bool {
+		for _, c := range queue.commits {
+			if flags[c.Hash] != reachableFromBoth || requeued[c.Hash] {
+				return true
+			}
+		}
+		return false
+	}
+	for queue.Len() > 0 && interesting() {
+		c := heap.Pop(queue).(*object.Commit)
+		if walked[c.Hash] {
+			continue
+		}
+		walked[c.Hash] = true
+		delete(requeued, c.Hash)
+		flag := flags[c.Hash]
+		counts[flag]++
+		for _, p := range c.ParentHashes {
+			if err := mark(p, flag); err != nil {
+				return 0, 0, err
+			}
+		}
+	}
+	return counts[reachableFromLocal], counts[reachableFromUpstream], nil
+}
+
+// commitQueue is a heap of commits, newest first
+type commitQueue struct {
+	commits []*object.Commit
+}
+
+func (q *commitQueue) Len() int { return len(q.commits) }
+
+func (q *commitQueue) Less(i, j int) bool {
+	return q.commits[i].Committer.When.After(q.commits[j].Committer.When)
+}
+
+func (q *commitQueue) Swap(i, j int) { q.commits[i], q.commits[j] = q.commits[j], q.commits[i] }
+
+func (q *commitQueue) Push(x interface{}) { q.commits = append(q.commits, x.(*object.Commit)) }
+
+func (q *commitQueue) Pop() interface{} {
+	c := q.commits[len(q.commits)-1]
+	q.commits = q.commits[:len(q.commits)-1]
+	return c
+}
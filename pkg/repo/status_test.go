@@ -0,0 +1,185 @@
+package repo
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	billy "gopkg.in/src-d/go-billy.v4"
+	"gopkg.in/src-d/go-billy.v4/memfs"
+	"gopkg.in/src-d/go-billy.v4/util"
+	git "gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/config"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+	"gopkg.in/src-d/go-git.v4/plumbing/cache"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+	"gopkg.in/src-d/go-git.v4/storage/filesystem"
+)
+
+type LocalStatusSuite struct {
+	suite.Suite
+	fs     billy.Filesystem
+	repoFs billy.Filesystem
+	r      *git.Repository
+}
+
+func (s *LocalStatusSuite) SetupTest() {
+	s.fs = memfs.New()
+	s.repoFs, _ = s.fs.Chroot("/repos/hermes")
+	dot, _ := s.repoFs.Chroot(".git")
+	r, err := git.Init(filesystem.NewStorage(dot, cache.NewObjectLRU(cache.DefaultMaxSize)), s.repoFs)
+	s.Nil(err, "Setup should init a repo")
+	s.r = r
+}
+
+func (s *LocalStatusSuite) commit(file, content string) plumbing.Hash {
+	s.Nil(util.WriteFile(s.repoFs, file, []byte(content), 0644))
+	wt, _ := s.r.Worktree()
+	_, err := wt.Add(file)
+	s.Nil(err)
+	h, err := wt.Commit(content, &git.CommitOptions{
+		Author: &object.Signature{
+			Name:  "hermes",
+			Email: "hermes@example.com",
+			When:  time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC),
+		},
+	})
+	s.Nil(err)
+	return h
+}
+
+// storeCommit stores a commit with the message and parents made at the time
+func (s *LocalStatusSuite) storeCommit(message string, when time.Time, parents ...plumbing.Hash) plumbing.Hash {
+	sig := object.Signature{Name: "hermes", Email: "hermes@example.com", When: when}
+	c := &object.Commit{
+		Author:       sig,
+		Committer:    sig,
+		Message:      message,
+		TreeHash:     plumbing.ZeroHash,
+		ParentHashes: parents,
+	}
+	obj := s.r.Storer.NewEncodedObject()
+	s.Nil(c.Encode(obj))
+	h, err := s.r.Storer.SetEncodedObject(obj)
+	s.Nil(err)
+	return h
+}
+
+// track sets the upstream of branch to origin's branch of the
+// same name, at h
+func (s *LocalStatusSuite) track(branch string, h plumbing.Hash) {
+	s.Nil(s.r.Storer.SetReference(plumbing.NewHashReference(plumbing.NewRemoteReferenceName("origin", branch), h)))
+	cfg, _ := s.r.Config()
+	cfg.Branches[branch] = &config.Branch{
+		Name:   branch,
+		Remote: "origin",
+		Merge:  plumbing.NewBranchReferenceName(branch),
+	}
+	s.Nil(s.r.Storer.SetConfig(cfg))
+}
+
+func (s *LocalStatusSuite) TestAheadBehind() {
+	day := func(d int) time.Time {
+		return time.Date(2020, 1, d, 0, 0, 0, 0, time.UTC)
+	}
+	base := s.storeCommit("base", day(1))
+	for d := 2; d < 10; d++ {
+		base = s.storeCommit("base", day(d), base)
+	}
+	local := s.storeCommit("local", day(10), base)
+	upstream := s.storeCommit("upstream", day(11), s.storeCommit("upstream", day(10), base))
+
+	ahead, behind, err := aheadBehind(s.r, local, local)
+	s.Nil(err)
+	s.Equal([]int{0, 0}, []int{ahead, behind})
+
+	ahead, behind, err = aheadBehind(s.r, local, upstream)
+	s.Nil(err)
+	s.Equal([]int{1, 2}, []int{ahead, behind}, "Diverged branches should count the commits on each side")
+
+	merge := s.storeCommit("merge", day(12), local, upstream)
+	ahead, behind, err = aheadBehind(s.r, merge, upstream)
+	s.Nil(err)
+	s.Equal([]int{2, 0}, []int{ahead, behind}, "A merged upstream should not be behind")
+
+	// a commit dated before the history it is made on
+	skewed := s.storeCommit("skewed", day(1), s.storeCommit("skewed", day(13), upstream))
+	ahead, behind, err = aheadBehind(s.r, skewed, merge)
+	s.Nil(err)
+	s.Equal([]int{2, 2}, []int{ahead, behind}, "Clock skew should not change the counts")
+}
+
+func (s *LocalStatusSuite) TestNoCommits() {
+	status, err := GetLocalStatus(s.fs, "/repos/hermes")
+	s.Nil(err)
+	s.Equal("master", status.Branch)
+	s.True(status.NoUpstream)
+	s.True(status.LastCommit.IsZero())
+}
+
+func (s *LocalStatusSuite) TestNoUpstream() {
+	s.commit("README.md", "hermes")
+	status, err := GetLocalStatus(s.fs, "/repos/hermes")
+	s.Nil(err)
+	s.True(status.NoUpstream)
+	s.Equal(1, status.UnpushedBranches)
+	s.True(status.Unpushed(), "A branch without an upstream is unpushed")
+	s.Equal(time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC), status.LastCommit.UTC())
+}
+
+func (s *LocalStatusSuite) TestAheadDirtyAndStashed() {
+	s.track("master", s.commit("README.md", "hermes"))
+	s.commit("main.go", "package main")
+
+	s.Nil(util.WriteFile(s.repoFs, "README.md", []byte("changed"), 0644))
+	s.Nil(util.WriteFile(s.repoFs, "notes.txt", []byte("notes"), 0644))
+	s.Nil(util.WriteFile(s.repoFs, ".git/logs/refs/stash", []byte("a b stash@{0}\nc d stash@{1}\n"), 0644))
+
+	status, err := GetLocalStatus(s.fs, "/repos/hermes")
+	s.Nil(err)
+	s.False(status.NoUpstream)
+	s.Equal(1, status.Ahead)
+	s.Equal(0, status.Behind)
+	s.Equal(1, status.UnpushedBranches)
+	s.True(status.Unpushed())
+	s.True(status.Dirty, "Changed tracked files should be dirty")
+	s.Equal(1, status.Untracked)
+	s.Equal(2, status.Stashes)
+}
+
+func (s *LocalStatusSuite) TestDetached() {
+	h := s.commit("README.md", "hermes")
+	s.track("master", h)
+	s.Nil(s.r.Storer.SetReference(plumbing.NewHashReference(plumbing.HEAD, h)))
+	status, err := GetLocalStatus(s.fs, "/repos/hermes")
+	s.Nil(err)
+	s.Empty(status.Branch)
+	s.False(status.Unpushed())
+}
+
+func (s *LocalStatusSuite) TestUnpushedBranches() {
+	pushed := s.commit("README.md", "hermes")
+	s.track("master", pushed)
+
+	s.track("feature", pushed)
+	feature := s.storeCommit("feature", time.Date(2020, 1, 3, 0, 0, 0, 0, time.UTC), pushed)
+	s.Nil(s.r.Storer.SetReference(plumbing.NewHashReference(plumbing.NewBranchReferenceName("feature"), feature)))
+	s.track("merged", pushed)
+	s.Nil(s.r.Storer.SetReference(plumbing.NewHashReference(plumbing.NewBranchReferenceName("merged"), pushed)))
+
+	status, err := GetLocalStatus(s.fs, "/repos/hermes")
+	s.Nil(err)
+	s.Equal("master", status.Branch)
+	s.Equal(0, status.Ahead, "The current branch should be up to date")
+	s.Equal(1, status.UnpushedBranches, "A branch other than HEAD's which is ahead should be counted")
+	s.True(status.Unpushed())
+
+	s.Nil(s.r.Storer.SetReference(plumbing.NewHashReference(plumbing.NewBranchReferenceName("wip"), pushed)))
+	status, err = GetLocalStatus(s.fs, "/repos/hermes")
+	s.Nil(err)
+	s.Equal(2, status.UnpushedBranches, "A branch without an upstream should be counted")
+}
+
+func TestLocalStatusSuite(t *testing.T) {
+	suite.Run(t, new(LocalStatusSuite))
+}
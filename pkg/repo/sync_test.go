@@ -0,0 +1,138 @@
+package repo
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// syncCase is a state of the main branch of a clone, whose upstream
+// has a commit the clone has not fetched yet unless the case says so
+type syncCase struct {
+	name string
+	// setup puts the clone at work into the case's state
+	setup func(t *testing.T, dir, work string)
+	want  SyncResult
+}
+
+var syncCases = []syncCase{
+	{
+		name: "behind",
+		setup: func(t *testing.T, dir, work string) {
+			pushUpstream(t, dir)
+		},
+		want: SyncResult{Branch: "main", Behind: 1, Updated: true},
+	},
+	{
+		name: "ahead",
+		setup: func(t *testing.T, dir, work string) {
+			commitFile(t, work, "local.txt", "local")
+		},
+		want: SyncResult{Branch: "main", Ahead: 1},
+	},
+	{
+		name: "diverged",
+		setup: func(t *testing.T, dir, work string) {
+			pushUpstream(t, dir)
+			commitFile(t, work, "local.txt", "local")
+		},
+		want: SyncResult{Branch: "main", Ahead: 1, Behind: 1},
+	},
+	{
+		name: "dirty",
+		setup: func(t *testing.T, dir, work string) {
+			pushUpstream(t, dir)
+			assert.Nil(t, ioutil.WriteFile(filepath.Join(work, "README.md"), []byte("changed"), 0644))
+		},
+		want: SyncResult{Branch: "main", Dirty: true, Behind: 1},
+	},
+	{
+		name: "detached HEAD",
+		setup: func(t *testing.T, dir, work string) {
+			pushUpstream(t, dir)
+			runGit(t, work, "checkout", "--quiet", "--detach")
+		},
+		want: SyncResult{},
+	},
+	{
+		name: "no upstream",
+		setup: func(t *testing.T, dir, work string) {
+			pushUpstream(t, dir)
+			runGit(t, work, "checkout", "--quiet", "-b", "topic")
+		},
+		want: SyncResult{Branch: "topic", NoUpstream: true},
+	},
+}
+
+// runSyncCases syncs a clone in each state with the cloner, which is
+// created for the dir holding the clone along with the clone's path,
+// and checks only a clone which is behind has its HEAD moved
+func runSyncCases(t *testing.T, newCloner func(dir string) (Cloner, string)) {
+	for _, c := range syncCases {
+		t.Run(c.name, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "hermes-sync")
+			assert.Nil(t, err)
+			defer os.RemoveAll(dir)
+			work := setupSyncClone(t, dir)
+			c.setup(t, dir, work)
+			before := runGit(t, work, "rev-parse", "HEAD")
+
+			cloner, path := newCloner(dir)
+			res, err := cloner.Sync(path, &SyncOptions{})
+			assert.Nil(t, err)
+			assert.Equal(t, &c.want, res)
+
+			after := runGit(t, work, "rev-parse", "HEAD")
+			if c.want.Updated {
+				assert.NotEqual(t, before, after, "HEAD should be fast-forwarded")
+				assert.Equal(t, runGit(t, work, "rev-parse", "origin/main"), after)
+			} else {
+				assert.Equal(t, before, after, "HEAD should not move")
+			}
+		})
+	}
+}
+
+// setupSyncClone creates an upstream repo in dir with one commit on
+// main, and returns the path of a clone of it
+func setupSyncClone(t *testing.T, dir string) string {
+	seed := filepath.Join(dir, "seed")
+	runGit(t, dir, "init", "--quiet", seed)
+	runGit(t, seed, "symbolic-ref", "HEAD", "refs/heads/main")
+	commitFile(t, seed, "README.md", "hermes")
+	runGit(t, dir, "clone", "--quiet", "--bare", seed, filepath.Join(dir, "upstream.git"))
+
+	work := filepath.Join(dir, "clone")
+	runGit(t, dir, "clone", "--quiet", filepath.Join(dir, "upstream.git"), work)
+	return work
+}
+
+// pushUpstream pushes a commit to main of the upstream repo in dir
+// from the seed repo
+func pushUpstream(t *testing.T, dir string) {
+	seed := filepath.Join(dir, "seed")
+	commitFile(t, seed, "upstream.txt", "upstream")
+	runGit(t, seed, "push", "--quiet", filepath.Join(dir, "upstream.git"), "main")
+}
+
+func commitFile(t *testing.T, work, name, content string) {
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(work, name), []byte(content), 0644))
+	runGit(t, work, "add", name)
+	runGit(t, work, "commit", "--quiet", "-m", name)
+}
+
+// runGit runs git in dir and returns its trimmed output,
+// failing the test when it fails
+func runGit(t *testing.T, dir string, args ...string) string {
+	args = append([]string{"-C", dir, "-c", "user.name=hermes", "-c", "user.email=hermes@example.com"}, args...)
+	out, err := exec.Command("git", args...).CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %s: %s", strings.Join(args, " "), out)
+	}
+	return strings.TrimSpace(string(out))
+}
@@ -1,16 +1,18 @@
+//go:build !gogit
 // +build !gogit
 
 package repo
 
 import (
 	"bufio"
+	"errors"
+	"fmt"
 	"io"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"regexp"
 	"strings"
-
-	"golang.org/x/sync/errgroup"
 )
 
 func init() {
@@ -26,26 +28,185 @@ type CallThroughCloner struct{}
 
 // Clone clones a repository
 func (c *CallThroughCloner) Clone(path string, opts *CloneOptions) error {
-	cmd := exec.Command("git", "clone", "--progress", opts.URL, path)
-	cmd.Stdout = os.Stdout
+	authArgs, env := gitAuth(opts.Auth)
+	cmd := exec.Command("git", append(authArgs, cloneArgs(path, opts)...)...)
+	cmd.Env = append(os.Environ(), env...)
+	cmd.Stdout = os.Stderr
 
 	stderrPipe, err := cmd.StderrPipe()
 	if err != nil {
 		return err
 	}
 
-	var g errgroup.Group
-	g.Go(func() error {
-		return filterGitErrors(os.Stdout, stderrPipe)
-	})
-
 	if err := cmd.Start(); err != nil {
 		return err
 	}
 
-	cmd.Wait()
+	// git's output is read until it closes stderr before waiting for it,
+	// as Wait closes the pipe and lines not yet read would be lost
+	gitErr := filterGitErrors(opts.Progress, stderrPipe)
+	waitErr := cmd.Wait()
+	if gitErr != nil {
+		return gitErr
+	}
+	if waitErr != nil {
+		return fmt.Errorf("%w: %s", ErrCloneRepo, waitErr)
+	}
 
-	return g.Wait()
+	if len(opts.SparsePaths) > 0 {
+		args := append([]string{"sparse-checkout", "set", "--cone"}, opts.SparsePaths...)
+		if _, err := gitOutput(path, args...); err != nil {
+			return fmt.Errorf("%w: %s", ErrCloneRepo, err)
+		}
+	}
+	if opts.Submodules && opts.SubmoduleDepth > 0 {
+		if err := updateSubmodules(path, opts.SubmoduleDepth, opts.SubmoduleJobs, opts.Auth); err != nil {
+			return fmt.Errorf("%w: %s", ErrCloneRepo, err)
+		}
+	}
+	if err := c.Configure(path, opts.GitConfig); err != nil {
+		return err
+	}
+	if opts.LFS {
+		return fetchLFS(appFs, path, opts.Auth)
+	}
+	return nil
+}
+
+// Configure sets the entries in the local git config of the repository at path
+func (c *CallThroughCloner) Configure(path string, config map[string]string) error {
+	for _, key := range SortedKeys(config) {
+		if _, err := gitOutput(path, "config", key, config[key]); err != nil {
+			return fmt.Errorf("%w: %s", ErrConfigRepo, err)
+		}
+	}
+	return nil
+}
+
+// updateSubmodules clones the submodules of the repo at path, and
+// theirs, down to depth levels of nesting. git clone only clones
+// every level with --recurse-submodules
+func updateSubmodules(path string, depth, jobs int, auth AuthMethod) error {
+	if depth == 0 {
+		return nil
+	}
+	args := []string{"submodule", "update", "--init"}
+	if jobs > 0 {
+		args = append(args, fmt.Sprintf("--jobs=%d", jobs))
+	}
+	if _, err := authGitOutput(path, auth, args...); err != nil {
+		return err
+	}
+
+	paths, err := gitOutput(path, "submodule", "foreach", "--quiet", "echo $sm_path")
+	if err != nil {
+		return err
+	}
+	for _, p := range strings.Fields(paths) {
+		if err := updateSubmodules(filepath.Join(path, p), depth-1, jobs, auth); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// cloneArgs returns the arguments to git to clone with the options
+func cloneArgs(path string, opts *CloneOptions) []string {
+	args := []string{"clone", "--progress"}
+	if opts.Depth > 0 {
+		args = append(args, fmt.Sprintf("--depth=%d", opts.Depth))
+	}
+	if opts.SingleBranch {
+		args = append(args, "--single-branch")
+	}
+	if opts.Reference != "" {
+		args = append(args, fmt.Sprintf("--branch=%s", opts.Reference))
+	}
+	if opts.Filter != "" {
+		args = append(args, fmt.Sprintf("--filter=%s", opts.Filter))
+	}
+	if len(opts.SparsePaths) > 0 {
+		args = append(args, "--sparse")
+	}
+	if opts.Submodules && opts.SubmoduleDepth == 0 {
+		args = append(args, "--recurse-submodules")
+		if opts.SubmoduleJobs > 0 {
+			args = append(args, fmt.Sprintf("--jobs=%d", opts.SubmoduleJobs))
+		}
+	}
+	return append(args, opts.URL, path)
+}
+
+// Pull fast-forwards the current branch of the repository at path
+func (c *CallThroughCloner) Pull(path string, opts *PullOptions) error {
+	if _, err := authGitOutput(path, opts.Auth, "pull", "--ff-only"); err != nil {
+		return fmt.Errorf("%w: %s", ErrPullRepo, err)
+	}
+	return nil
+}
+
+// Sync fetches the repository at path and fast-forwards its current
+// branch when it is behind its upstream and has no local changes
+func (c *CallThroughCloner) Sync(path string, opts *SyncOptions) (*SyncResult, error) {
+	if _, err := authGitOutput(path, opts.Auth, "fetch", "--quiet"); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrFetchRepo, err)
+	}
+
+	res := &SyncResult{}
+	branch, err := gitOutput(path, "symbolic-ref", "--short", "--quiet", "HEAD")
+	if err != nil {
+		// HEAD is detached
+		return res, nil
+	}
+	res.Branch = branch
+
+	if _, err := gitOutput(path, "rev-parse", "--abbrev-ref", "--symbolic-full-name", "@{upstream}"); err != nil {
+		res.NoUpstream = true
+		return res, nil
+	}
+
+	status, err := gitOutput(path, "status", "--porcelain", "--untracked-files=no")
+	if err != nil {
+		return nil, err
+	}
+	res.Dirty = status != ""
+
+	counts, err := gitOutput(path, "rev-list", "--left-right", "--count", "HEAD...@{upstream}")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := fmt.Sscan(counts, &res.Ahead, &res.Behind); err != nil {
+		return nil, err
+	}
+
+	if res.CanFastForward() {
+		if _, err := gitOutput(path, "merge", "--ff-only", "--quiet", "@{upstream}"); err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrPullRepo, err)
+		}
+		res.Updated = true
+	}
+	return res, nil
+}
+
+// gitOutput runs git in the repository at path and returns its trimmed
+// output, when git fails the error holds what it wrote to stderr
+func gitOutput(path string, args ...string) (string, error) {
+	return authGitOutput(path, nil, args...)
+}
+
+// authGitOutput runs git in the repository at path like gitOutput,
+// authenticating with the auth
+func authGitOutput(path string, auth AuthMethod, args ...string) (string, error) {
+	authArgs, env := gitAuth(auth)
+	cmd := exec.Command("git", append(append(authArgs, "-C", path), args...)...)
+	cmd.Env = append(os.Environ(), env...)
+	out, err := cmd.Output()
+	if exitErr, ok := err.(*exec.ExitError); ok {
+		return "", errors.New(strings.TrimSpace(string(exitErr.Stderr)))
+	} else if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
 }
 
 type gitOuput struct {
@@ -54,7 +215,9 @@ type gitOuput struct {
 	err       error
 }
 
-func filterGitErrors(w io.Writer, r io.Reader) error {
+// filterGitErrors reads git's output, reporting its progress and
+// messages, and returns the error for why git failed
+func filterGitErrors(reporter ProgressReporter, r io.Reader) error {
 	errRegex := "^fatal: (.+)$"
 	re, err := regexp.Compile(errRegex)
 	if err != nil {
@@ -62,25 +225,35 @@ func filterGitErrors(w io.Writer, r io.Reader) error {
 	}
 
 	scanner := bufio.NewScanner(r)
-	scanner.Split(bufio.ScanLines)
+	scanner.Split(scanProgressLines)
 
-	var finalErr error
+	// the cause is often printed before the fatal line, such as
+	// ssh's Permission denied (publickey), so every message is kept
+	var messages []string
+	fatal := ""
+	alreadyExists := false
 	for scanner.Scan() {
 		line := scanner.Text()
+		if _, ok := parseProgress(strings.TrimSpace(strings.TrimPrefix(line, "remote:"))); !ok {
+			messages = append(messages, line)
+		}
 		matches := re.FindStringSubmatch(line)
 		if len(matches) > 0 {
-			message := ""
-			if len(matches) > 1 {
-				message = matches[1]
+			if strings.Contains(matches[1], "already exists and is not an empty directory") {
+				alreadyExists = true
 			}
-			if strings.Contains(message, "already exists and is not an empty directory") {
-				finalErr = ErrRepoAlreadyExists
-			} else {
-				finalErr = ErrCloneRepo
+			if fatal == "" {
+				fatal = matches[1]
 			}
 		} else {
-			w.Write([]byte(line + "\n"))
+			reportLine(reporter, line)
 		}
 	}
-	return finalErr
+	switch {
+	case alreadyExists:
+		return ErrRepoAlreadyExists
+	case fatal == "":
+		return nil
+	}
+	return classifyOutput(messages, fatal)
 }
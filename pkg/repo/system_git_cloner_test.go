@@ -0,0 +1,15 @@
+//go:build !gogit
+// +build !gogit
+
+package repo
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestCallThroughClonerSync(t *testing.T) {
+	runSyncCases(t, func(dir string) (Cloner, string) {
+		return &CallThroughCloner{}, filepath.Join(dir, "clone")
+	})
+}
@@ -0,0 +1,279 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	metaBucket    = []byte("meta")
+	remotesBucket = []byte("remotes")
+	reposBucket   = []byte("repos")
+	versionKey    = []byte("version")
+)
+
+// boltStorage is an implementation of Storage backed by a bbolt database.
+// Repos are keyed by name so lookups don't need to decode the whole cache
+// and every change is written in its own transaction as it is made.
+// Remotes returned by SearchRemote and ListRemotes do not have their Repos
+// populated, changes made to them are written on Save
+type boltStorage struct {
+	path    string
+	db      *bolt.DB
+	remotes map[string]*Remote
+	saveErr error
+}
+
+// NewBoltStorage creates a cache persisted to the bbolt database at path
+func NewBoltStorage(path string) Storage {
+	return &boltStorage{
+		path: path,
+	}
+}
+
+// Open the database, waiting up to lockTimeout for any other
+// hermes process which has it open to close it
+func (s *boltStorage) Open() error {
+	s.remotes = make(map[string]*Remote)
+	s.saveErr = nil
+	if s.db == nil {
+		db, err := bolt.Open(s.path, 0600, &bolt.Options{Timeout: lockTimeout})
+		if err == bolt.ErrTimeout {
+			return ErrCacheLocked
+		} else if err != nil {
+			return err
+		}
+		s.db = db
+	}
+
+	return s.db.Update(func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{metaBucket, remotesBucket, reposBucket} {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+		meta := tx.Bucket(metaBucket)
+		version := string(meta.Get(versionKey))
+		switch {
+		case version == "":
+			return meta.Put(versionKey, []byte(cacheFormatVersion))
+		case compareVersions(version, cacheFormatVersion) > 0:
+			s.saveErr = ErrCacheTooNew
+		}
+		return nil
+	})
+}
+
+// Save writes any changes made to remotes returned from the
+// cache, all other changes are written as they are made
+func (s *boltStorage) Save() error {
+	if s.saveErr != nil {
+		return s.saveErr
+	}
+	return s.db.Update(func(tx *bolt.Tx) error {
+		for _, r := range s.remotes {
+			if err := putRemote(tx, r); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Close the database
+func (s *boltStorage) Close() error {
+	if s.db == nil {
+		return nil
+	}
+	db := s.db
+	s.db = nil
+	return db.Close()
+}
+
+func (s *boltStorage) update(fn func(tx *bolt.Tx) error) error {
+	if s.saveErr != nil {
+		return s.saveErr
+	}
+	return s.db.Update(fn)
+}
+
+// AddRepository adds a repo to the cache
+func (s *boltStorage) AddRepository(repo *Repository) error {
+	remote := strings.Split(repo.Name, "/")[0]
+
+	return s.update(func(tx *bolt.Tx) error {
+		repos := tx.Bucket(reposBucket)
+		if repos.Get([]byte(repo.Name)) != nil {
+			return ErrRepoExists
+		}
+
+		if tx.Bucket(remotesBucket).Get([]byte(remote)) == nil {
+			remoteURL, err := url.Parse(fmt.Sprintf("https://%s", remote))
+			if err != nil {
+				return err
+			}
+			if err := putRemote(tx, &Remote{
+				Name: remote,
+				URL:  remoteURL.String(),
+			}); err != nil {
+				return err
+			}
+		}
+
+		raw, err := json.Marshal(repo)
+		if err != nil {
+			return err
+		}
+		return repos.Put([]byte(repo.Name), raw)
+	})
+}
+
+// UpdateRepository replaces the cached repo with the same name
+func (s *boltStorage) UpdateRepository(repo *Repository) error {
+	return s.update(func(tx *bolt.Tx) error {
+		repos := tx.Bucket(reposBucket)
+		if repos.Get([]byte(repo.Name)) == nil {
+			return ErrRepoNotFound
+		}
+		raw, err := json.Marshal(repo)
+		if err != nil {
+			return err
+		}
+		return repos.Put([]byte(repo.Name), raw)
+	})
+}
+
+// GetRepository returns the repo with exactly the given name
+// and true if present or nil and false if not
+func (s *boltStorage) GetRepository(name string) (*Repository, bool) {
+	var result *Repository
+	s.db.View(func(tx *bolt.Tx) error {
+		raw := tx.Bucket(reposBucket).Get([]byte(name))
+		if raw == nil {
+			return nil
+		}
+		repo := &Repository{}
+		if err := json.Unmarshal(raw, repo); err != nil {
+			return err
+		}
+		result = repo
+		return nil
+	})
+	return result, result != nil
+}
+
+// RemoveRepository a repo from the cache
+func (s *boltStorage) RemoveRepository(name string) error {
+	return s.update(func(tx *bolt.Tx) error {
+		repos := tx.Bucket(reposBucket)
+		if repos.Get([]byte(name)) == nil {
+			return ErrRepoNotFound
+		}
+		return repos.Delete([]byte(name))
+	})
+}
+
+// AddRemote adds a remote to the cache
+func (s *boltStorage) AddRemote(url, name, remoteType, protocol string) error {
+	return s.update(func(tx *bolt.Tx) error {
+		if tx.Bucket(remotesBucket).Get([]byte(name)) != nil {
+			return errRemoteExists
+		}
+		return putRemote(tx, &Remote{
+			Name:     name,
+			URL:      url,
+			Protocol: protocol,
+			Type:     remoteType,
+		})
+	})
+}
+
+// SearchRepositories will search the cache for any repos that match the
+// needle string, only repos with a matching name are decoded
+func (s *boltStorage) SearchRepositories(needle string) []Repository {
+	query := parseQuery(needle)
+	var results []Repository
+
+	s.db.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket(reposBucket).Cursor()
+		for k, v := c.First(); k != nil; k, v = c.Next() {
+			if !query.matchesName(string(k)) {
+				continue
+			}
+			var repo Repository
+			if err := json.Unmarshal(v, &repo); err != nil {
+				continue
+			}
+			if query.matches(string(k), &repo) {
+				results = append(results, repo)
+			}
+		}
+		return nil
+	})
+
+	// keys are sorted so results are in order by name
+	return results
+}
+
+// SearchRemote will return the Remote and true if present or an
+// empty remote and false if not
+func (s *boltStorage) SearchRemote(remote string) (*Remote, bool) {
+	if r, ok := s.remotes[remote]; ok {
+		return r, true
+	}
+
+	var result *Remote
+	s.db.View(func(tx *bolt.Tx) error {
+		raw := tx.Bucket(remotesBucket).Get([]byte(remote))
+		if raw == nil {
+			return nil
+		}
+		r := &Remote{}
+		if err := json.Unmarshal(raw, r); err != nil {
+			return err
+		}
+		result = r
+		return nil
+	})
+
+	if result == nil {
+		return &Remote{}, false
+	}
+	s.remotes[remote] = result
+	return result, true
+}
+
+// ListRemotes will return a list of all the remotes in the cache
+func (s *boltStorage) ListRemotes() []*Remote {
+	results := []*Remote{}
+	names := []string{}
+	s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(remotesBucket).ForEach(func(k, v []byte) error {
+			names = append(names, string(k))
+			return nil
+		})
+	})
+
+	for _, name := range names {
+		if r, ok := s.SearchRemote(name); ok {
+			results = append(results, r)
+		}
+	}
+	return results
+}
+
+// putRemote writes the remote without its repos, which
+// are stored in their own bucket
+func putRemote(tx *bolt.Tx, r *Remote) error {
+	stored := *r
+	stored.Repos = nil
+	raw, err := json.Marshal(&stored)
+	if err != nil {
+		return err
+	}
+	return tx.Bucket(remotesBucket).Put([]byte(r.Name), raw)
+}
@@ -0,0 +1,184 @@
+package storage
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	bolt "go.etcd.io/bbolt"
+	"gopkg.in/src-d/go-billy.v4/memfs"
+)
+
+type BoltStorageSuite struct {
+	suite.Suite
+	dir  string
+	path string
+}
+
+func (s *BoltStorageSuite) SetupTest() {
+	dir, err := ioutil.TempDir("", "hermes-bolt")
+	s.Nil(err, "Setup should create a temp dir")
+	s.dir = dir
+	s.path = filepath.Join(dir, "cache.db")
+}
+
+func (s *BoltStorageSuite) TearDownTest() {
+	os.RemoveAll(s.dir)
+}
+
+func (s *BoltStorageSuite) TestOpenEmpty() {
+	store := NewBoltStorage(s.path)
+	s.Nil(store.Open(), "A missing database should be created")
+	s.Empty(store.ListRemotes(), "There should be no remotes")
+	s.Empty(store.SearchRepositories(""), "There should be no repos")
+	s.Nil(store.Close())
+}
+
+func (s *BoltStorageSuite) TestAddThenReopen() {
+	store := NewBoltStorage(s.path)
+	s.Nil(store.Open())
+	s.Nil(store.AddRemote("https://github.com", "github.com", "github", "https"))
+	s.Nil(store.AddRepository(&Repository{
+		Name: "github.com/TheHipbot/hermes",
+		Path: "/repos/github.com/TheHipbot/hermes",
+	}))
+	s.Nil(store.AddRepository(&Repository{
+		Name: "gitlab.com/TheHipbot/dotfiles",
+		Path: "/repos/gitlab.com/TheHipbot/dotfiles",
+	}))
+	s.NotNil(store.AddRepository(&Repository{
+		Name: "github.com/TheHipbot/hermes",
+	}), "A repo should not be added twice")
+	s.NotNil(store.AddRemote("https://github.com", "github.com", "github", "https"), "A remote should not be added twice")
+	s.Nil(store.Close())
+
+	store = NewBoltStorage(s.path)
+	s.Nil(store.Open())
+	defer store.Close()
+	repos := store.SearchRepositories("THEHIPBOT")
+	s.Len(repos, 2, "Search should be case insensitive")
+	s.Equal("github.com/TheHipbot/hermes", repos[0].Name, "Repos should be sorted by name")
+	s.Equal("/repos/github.com/TheHipbot/hermes", repos[0].Path)
+
+	gitlab, ok := store.SearchRemote("gitlab.com")
+	s.True(ok, "A remote should be created for a repo with an unknown remote")
+	s.Equal("https://gitlab.com", gitlab.URL)
+	s.Len(store.ListRemotes(), 2)
+}
+
+func (s *BoltStorageSuite) TestRemoveRepository() {
+	store := NewBoltStorage(s.path)
+	s.Nil(store.Open())
+	defer store.Close()
+	s.Nil(store.AddRepository(&Repository{
+		Name: "github.com/TheHipbot/hermes",
+	}))
+	s.Nil(store.RemoveRepository("github.com/TheHipbot/hermes"))
+	s.Empty(store.SearchRepositories("hermes"), "Removed repo should not be found")
+	s.NotNil(store.RemoveRepository("github.com/TheHipbot/hermes"), "Removing a missing repo should error")
+}
+
+func (s *BoltStorageSuite) TestUpdateAndSearchDetails() {
+	store := NewBoltStorage(s.path)
+	s.Nil(store.Open())
+	defer store.Close()
+	s.Nil(store.AddRepository(&Repository{
+		Name: "github.com/TheHipbot/hermes",
+	}))
+	s.Nil(store.AddRepository(&Repository{
+		Name: "github.com/TheHipbot/dotfiles",
+	}))
+
+	repo, ok := store.GetRepository("github.com/TheHipbot/hermes")
+	s.True(ok)
+	repo.Language = "Go"
+	s.Nil(store.UpdateRepository(repo))
+	s.Equal(ErrRepoNotFound, store.UpdateRepository(&Repository{Name: "github.com/TheHipbot/herm"}))
+
+	results := store.SearchRepositories("thehipbot lang:go")
+	s.Len(results, 1, "Updated details should be searchable")
+	s.Equal("Go", results[0].Language)
+}
+
+func (s *BoltStorageSuite) TestSaveRemoteChanges() {
+	store := NewBoltStorage(s.path)
+	s.Nil(store.Open())
+	s.Nil(store.AddRemote("https://github.com", "github.com", "github", "https"))
+	remote, _ := store.SearchRemote("github.com")
+	remote.Protocol = "ssh"
+	remote.Meta = map[string]string{"user": "TheHipbot"}
+	s.Nil(store.Save())
+	s.Nil(store.Close())
+
+	s.Nil(store.Open())
+	defer store.Close()
+	remote, ok := store.SearchRemote("github.com")
+	s.True(ok)
+	s.Equal("ssh", remote.Protocol, "Changes to a remote should be saved")
+	s.Equal("TheHipbot", remote.Meta["user"])
+}
+
+func (s *BoltStorageSuite) TestOpenNewerDatabase() {
+	store := NewBoltStorage(s.path)
+	s.Nil(store.Open())
+	s.Nil(store.Close())
+
+	db, err := bolt.Open(s.path, 0600, nil)
+	s.Nil(err)
+	s.Nil(db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(metaBucket).Put(versionKey, []byte("99.0.0"))
+	}))
+	s.Nil(db.Close())
+
+	s.Nil(store.Open(), "Newer database should still be readable")
+	defer store.Close()
+	s.Equal(ErrCacheTooNew, store.AddRemote("https://github.com", "github.com", "github", "https"), "Newer database should never be written")
+	s.Equal(ErrCacheTooNew, store.Save())
+}
+
+func (s *BoltStorageSuite) TestCopyFromFile() {
+	fs := memfs.New()
+	src := NewFileStorage(fs, "/cache.json")
+	s.Nil(src.Open())
+	s.Nil(src.AddRemote("https://github.com", "github.com", "github", "ssh"))
+	remote, _ := src.SearchRemote("github.com")
+	remote.Meta = map[string]string{"user": "TheHipbot"}
+	remote.GitConfig = map[string]string{"user.email": "jeremy@corp.com"}
+	s.Nil(src.AddRepository(&Repository{
+		Name: "github.com/TheHipbot/hermes",
+		Path: "/repos/github.com/TheHipbot/hermes",
+	}))
+
+	dst := NewBoltStorage(s.path)
+	s.Nil(dst.Open())
+	defer dst.Close()
+	s.Nil(Copy(dst, src), "Cache should be copied")
+	s.Equal(src.SearchRepositories(""), dst.SearchRepositories(""), "Repos should be copied")
+	copied, ok := dst.SearchRemote("github.com")
+	s.True(ok, "Remotes should be copied")
+	s.Equal("ssh", copied.Protocol)
+	s.Equal("TheHipbot", copied.Meta["user"], "Remote meta should be copied")
+	s.Equal("jeremy@corp.com", copied.GitConfig["user.email"], "Remote git config should be copied")
+
+	s.Equal(ErrCacheNotEmpty, Copy(dst, src), "Copying into a cache with data should error")
+}
+
+func (s *BoltStorageSuite) TestLockTimeout() {
+	defer func(timeout time.Duration) {
+		lockTimeout = timeout
+	}(lockTimeout)
+	lockTimeout = 50 * time.Millisecond
+
+	store := NewBoltStorage(s.path)
+	s.Nil(store.Open())
+	defer store.Close()
+	s.Equal(ErrCacheLocked, NewBoltStorage(s.path).Open(),
+		"Opening a database another process has open should time out")
+}
+
+func TestBoltStorageSuite(t *testing.T) {
+	suite.Run(t, new(BoltStorageSuite))
+}
@@ -3,6 +3,7 @@
 package storage
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -14,7 +15,24 @@ import (
 )
 
 const (
-	cacheFormatVersion = "0.0.1"
+	cacheFormatVersion = "0.1.0"
+)
+
+var (
+	// ErrCorruptCache is returned when the cache could not be parsed
+	ErrCorruptCache = errors.New("cache is corrupt")
+
+	// ErrRepoExists is returned when adding a repo which is already cached
+	ErrRepoExists = errors.New("repo already exists")
+
+	// ErrRepoNotFound is returned when a repo is not in the cache
+	ErrRepoNotFound = errors.New("repo not found")
+
+	// ErrCacheLocked is returned when the cache could not be opened
+	// within lockTimeout as another hermes process has it open
+	ErrCacheLocked = errors.New("cache is locked by another hermes process")
+
+	errRemoteExists = errors.New("remote already exists")
 )
 
 // storer persists the cache
@@ -26,10 +44,12 @@ type storer interface {
 
 // Storage interface to open and save repo Storage
 type Storage interface {
-	Open()
+	Open() error
 	Save() error
 	Close() error
 	AddRepository(repo *Repository) error
+	UpdateRepository(repo *Repository) error
+	GetRepository(name string) (*Repository, bool)
 	RemoveRepository(name string) error
 	AddRemote(url, name, remoteType, protocol string) error
 	SearchRepositories(needle string) []Repository
@@ -39,6 +59,8 @@ type Storage interface {
 
 type storage struct {
 	storer  storer
+	file    *cacheFile
+	saveErr error
 	Version string             `json:"version"`
 	Remotes map[string]*Remote `json:"remotes"`
 }
@@ -50,33 +72,101 @@ func NewStorage(storer storer) Storage {
 	}
 }
 
-// Open the cache from the provided storer
-func (s *storage) Open() {
-	_, err := s.storer.Seek(0, 0)
-	raw, err := ioutil.ReadAll(s.storer)
-	var result storage
+// Open the cache from the provided storer, if the cache cannot be
+// parsed an empty cache is opened and ErrCorruptCache is returned.
+// Caches of an older format version are backed up then migrated
+func (s *storage) Open() error {
+	s.Version = cacheFormatVersion
+	s.Remotes = make(map[string]*Remote)
+	s.saveErr = nil
+
+	raw, err := s.read()
 	if err != nil {
-		s.Version = cacheFormatVersion
-		s.Remotes = make(map[string]*Remote)
-	} else {
+		return err
+	}
+	if len(bytes.TrimSpace(raw)) == 0 {
+		return nil
+	}
+
+	result := storage{}
+	if err := json.Unmarshal(raw, &result); err != nil {
+		s.saveErr = ErrCorruptCache
+		return s.corruptErr(err)
+	}
+
+	version := result.Version
+	if version == "" {
+		version = initialFormatVersion
+	}
+	switch {
+	case compareVersions(version, cacheFormatVersion) > 0:
+		// keep the newer cache readable but never overwrite it
+		s.saveErr = ErrCacheTooNew
+	case compareVersions(version, cacheFormatVersion) < 0:
+		if err := s.backup(raw, version); err != nil {
+			s.saveErr = err
+			return err
+		}
+		migrated, err := migrate(raw, version)
+		if err != nil {
+			s.saveErr = err
+			return err
+		}
 		result = storage{}
-		if err := json.Unmarshal(raw, &result); err != nil {
-			s.Version = cacheFormatVersion
-			s.Remotes = make(map[string]*Remote)
-		} else {
-			s.Version = result.Version
-			s.Remotes = result.Remotes
+		if err := json.Unmarshal(migrated, &result); err != nil {
+			s.saveErr = err
+			return err
 		}
 	}
+
+	s.Version = result.Version
+	if result.Remotes != nil {
+		s.Remotes = result.Remotes
+	}
+	return nil
+}
+
+// backup keeps a copy of the cache before it is migrated from version
+func (s *storage) backup(raw []byte, version string) error {
+	if s.file != nil {
+		return s.file.backupVersion(raw, version)
+	}
+	return nil
+}
+
+func (s *storage) read() ([]byte, error) {
+	if s.file != nil {
+		return s.file.read()
+	}
+	if _, err := s.storer.Seek(0, 0); err != nil {
+		return nil, err
+	}
+	return ioutil.ReadAll(s.storer)
+}
+
+func (s *storage) corruptErr(err error) error {
+	if s.file != nil {
+		return fmt.Errorf("%w: %s could not be parsed (%s), fix or remove it to continue, a backup of the previous cache may be found at %s", ErrCorruptCache, s.file.path, err, s.file.backupPath())
+	}
+	return fmt.Errorf("%w: %s", ErrCorruptCache, err)
 }
 
-// Save cache to ConfigFS
+// Save cache to ConfigFS, a cache which was corrupt or newer than
+// this version of hermes when opened is never saved so its data is kept
 func (s *storage) Save() error {
+	if s.saveErr != nil {
+		return s.saveErr
+	}
+
 	raw, err := json.Marshal(s)
 	if err != nil {
 		return err
 	}
 
+	if s.file != nil {
+		return s.file.write(raw)
+	}
+
 	_, err = s.storer.Seek(0, 0)
 	if err != nil {
 		return err
@@ -95,6 +185,9 @@ func (s *storage) Save() error {
 
 // Close cache storer
 func (s *storage) Close() error {
+	if s.file != nil {
+		return s.file.close()
+	}
 	return s.storer.Close()
 }
 
@@ -104,7 +197,7 @@ func (s *storage) AddRepository(repo *Repository) error {
 
 	if r, ok := s.Remotes[remote]; ok {
 		if _, ok := r.Repos[repo.Name]; ok {
-			return errors.New("repo already exists")
+			return ErrRepoExists
 		}
 		r.Repos[repo.Name] = repo
 	} else {
@@ -125,6 +218,35 @@ func (s *storage) AddRepository(repo *Repository) error {
 	return nil
 }
 
+// UpdateRepository replaces the cached repo with the same name
+func (s *storage) UpdateRepository(repo *Repository) error {
+	remote := strings.Split(repo.Name, "/")[0]
+
+	if r, ok := s.Remotes[remote]; ok {
+		if _, ok := r.Repos[repo.Name]; ok {
+			r.Repos[repo.Name] = repo
+			return nil
+		}
+	}
+
+	return ErrRepoNotFound
+}
+
+// GetRepository returns a copy of the repo with exactly the given
+// name and true if present or nil and false if not
+func (s *storage) GetRepository(name string) (*Repository, bool) {
+	remote := strings.Split(name, "/")[0]
+
+	if r, ok := s.Remotes[remote]; ok {
+		if repo, ok := r.Repos[name]; ok {
+			cached := *repo
+			return &cached, true
+		}
+	}
+
+	return nil, false
+}
+
 // RemoveRepository a repo from the cache
 func (s *storage) RemoveRepository(name string) error {
 	remote := strings.Split(name, "/")[0]
@@ -136,13 +258,13 @@ func (s *storage) RemoveRepository(name string) error {
 		}
 	}
 
-	return errors.New("repo not found")
+	return ErrRepoNotFound
 }
 
 // AddRemote adds a remote to the cache
 func (s *storage) AddRemote(url, name, remoteType, protocol string) error {
 	if _, ok := s.Remotes[name]; ok {
-		return errors.New("remote already exists")
+		return errRemoteExists
 	}
 
 	remote := &Remote{
@@ -158,13 +280,13 @@ func (s *storage) AddRemote(url, name, remoteType, protocol string) error {
 }
 
 // Search will search the cache for any repos that match the
-// needle string
+// needle string, see parseQuery for the search syntax
 func (s *storage) SearchRepositories(needle string) []Repository {
-	lowerSearch := strings.ToLower(needle)
+	query := parseQuery(needle)
 	var results []Repository
 	for _, remote := range s.Remotes {
 		for name, repo := range remote.Repos {
-			if strings.Contains(strings.ToLower(name), lowerSearch) {
+			if query.matches(name, repo) {
 				results = append(results, *repo)
 			}
 		}
@@ -129,7 +129,7 @@ func (s *StorageSuite) TestStorageOpenWithFileData() {
 	}
 	storage.Open()
 
-	s.Equal(storage.Version, "0.0.1", "storage format version should be 0.0.1")
+	s.Equal(storage.Version, cacheFormatVersion, "storage format version should be migrated to the current version")
 	s.NotNil(storage.Remotes["github.com"], "There should be repos in the github.com remote")
 	s.Equal(len(storage.Remotes["github.com"].Repos), 4, "There should be 4 repos in the github.com remote")
 	s.Equal(storage.Remotes["github.com"].Repos["github.com/TheHipbot/hermes"].Name, "github.com/TheHipbot/hermes", "github.com/TheHipbot/herme should be a repo in the github.com remote should be hermes")
@@ -334,6 +334,58 @@ func (s *StorageSuite) TestStorageSearchWithoutResults() {
 	s.Len(results, 0, "There no results")
 }
 
+func (s *StorageSuite) TestStorageSearchQualifiers() {
+	hermes := testStorage.Remotes["github.com"].Repos["github.com/TheHipbot/hermes"]
+	hermes.Language = "Go"
+	hermes.Topics = []string{"git", "CLI"}
+	hermes.Description = "Messenger of the Version Control Gods"
+	hermes.Visibility = "public"
+	dotfiles := testStorage.Remotes["github.com"].Repos["github.com/TheHipbot/dotfiles"]
+	dotfiles.Language = "Shell"
+	dotfiles.Archived = true
+
+	results := testStorage.SearchRepositories("lang:go")
+	s.Len(results, 1, "Only hermes is written in Go")
+	s.Equal("github.com/TheHipbot/hermes", results[0].Name)
+	s.Len(testStorage.SearchRepositories("topic:cli"), 1, "Topics should match case insensitively")
+	s.Len(testStorage.SearchRepositories("desc:version"), 1, "Descriptions should be searched")
+	s.Len(testStorage.SearchRepositories("visibility:public"), 1)
+	s.Len(testStorage.SearchRepositories("thehipbot archived:false"), 2, "Qualifiers should combine with the name")
+	s.Len(testStorage.SearchRepositories("gitlab lang:go"), 0, "Every term should match")
+	s.Len(testStorage.SearchRepositories("hipbot files"), 2, "Every name term should match")
+	s.Len(testStorage.SearchRepositories("foo:bar"), 0, "Unknown qualifiers should be matched against the name")
+	s.Len(testStorage.SearchRepositories("remote:GitHub.com"), 4, "Repos should be searchable by remote")
+	s.Len(testStorage.SearchRepositories("remote:github"), 0, "The remote name should match exactly")
+}
+
+func (s *StorageSuite) TestRepositoryTags() {
+	repo := testStorage.Remotes["github.com"].Repos["github.com/TheHipbot/hermes"]
+	repo.AddTags("OnCall", "frontend", "oncall")
+	s.Equal([]string{"frontend", "oncall"}, repo.Tags, "Tags should be lowercase, sorted and without duplicates")
+	s.True(repo.HasTag("ONCALL"))
+	s.Len(testStorage.SearchRepositories("tag:oncall"), 1, "Repos should be searchable by tag")
+	s.Len(testStorage.SearchRepositories("tag:oncall tag:backend"), 0, "Every tag should match")
+
+	repo.RemoveTags("frontend", "q4-migration")
+	s.Equal([]string{"oncall"}, repo.Tags)
+	repo.RemoveTags("oncall")
+	s.Nil(repo.Tags, "Removing every tag should leave none")
+}
+
+func (s *StorageSuite) TestGetAndUpdateRepository() {
+	repo, ok := testStorage.GetRepository("github.com/TheHipbot/hermes")
+	s.True(ok, "Repo should be found by its exact name")
+	repo.Description = "updated"
+	s.Empty(testStorage.Remotes["github.com"].Repos["github.com/TheHipbot/hermes"].Description, "Changes to the returned repo should not change the cache")
+
+	s.Nil(testStorage.UpdateRepository(repo))
+	s.Equal("updated", testStorage.Remotes["github.com"].Repos["github.com/TheHipbot/hermes"].Description, "Update should replace the cached repo")
+
+	_, ok = testStorage.GetRepository("github.com/TheHipbot/herm")
+	s.False(ok, "Only an exact name should be found")
+	s.Equal(ErrRepoNotFound, testStorage.UpdateRepository(&Repository{Name: "github.com/TheHipbot/herm"}))
+}
+
 func (s *StorageSuite) TestStorageSearchRemote() {
 	res, ok := testStorage.SearchRemote("github.com")
 	s.True(ok)
@@ -0,0 +1,49 @@
+package storage
+
+import (
+	"errors"
+)
+
+// ErrCacheNotEmpty is returned when copying into a cache which
+// already has remotes or repos
+var ErrCacheNotEmpty = errors.New("destination cache is not empty")
+
+// Copy copies every remote and repo in the open src cache into the open
+// dst cache, which must be empty. dst is saved once everything is copied
+func Copy(dst, src Storage) error {
+	if len(dst.ListRemotes()) > 0 || len(dst.SearchRepositories("")) > 0 {
+		return ErrCacheNotEmpty
+	}
+
+	for _, r := range src.ListRemotes() {
+		if err := dst.AddRemote(r.URL, r.Name, r.Type, r.Protocol); err != nil {
+			return err
+		}
+		if len(r.Meta) == 0 && len(r.GitConfig) == 0 {
+			continue
+		}
+		copied, _ := dst.SearchRemote(r.Name)
+		copied.Meta = copyMap(r.Meta)
+		copied.GitConfig = copyMap(r.GitConfig)
+	}
+
+	repos := src.SearchRepositories("")
+	for i := range repos {
+		if err := dst.AddRepository(&repos[i]); err != nil {
+			return err
+		}
+	}
+
+	return dst.Save()
+}
+
+func copyMap(m map[string]string) map[string]string {
+	if m == nil {
+		return nil
+	}
+	copied := make(map[string]string, len(m))
+	for k, v := range m {
+		copied[k] = v
+	}
+	return copied
+}
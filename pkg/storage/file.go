@@ -0,0 +1,176 @@
+package storage
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"time"
+
+	billy "gopkg.in/src-d/go-billy.v4"
+)
+
+const (
+	lockSuffix   = ".lock"
+	backupSuffix = ".bak"
+	tempSuffix   = ".tmp"
+)
+
+// lockTimeout is how long to wait for another hermes process
+// to close the cache before giving up with ErrCacheLocked
+var lockTimeout = 5 * time.Second
+
+// cacheFile persists the cache to a file on a billy.Filesystem. Writes
+// go to a temp file which is renamed over the cache so a crash never
+// leaves a partially written cache, and an advisory lock is held on a
+// lock file next to the cache from the first read until close
+type cacheFile struct {
+	fs       billy.Filesystem
+	path     string
+	lock     billy.File
+	backedUp bool
+}
+
+// NewFileStorage creates a cache persisted to the file at path
+// on the given filesystem
+func NewFileStorage(fs billy.Filesystem, path string) Storage {
+	return &storage{
+		file: &cacheFile{
+			fs:   fs,
+			path: path,
+		},
+	}
+}
+
+func (c *cacheFile) backupPath() string {
+	return c.path + backupSuffix
+}
+
+// acquire takes the lock on the cache, waiting up to lockTimeout
+// for any other hermes process holding it to close the cache
+func (c *cacheFile) acquire() error {
+	if c.lock != nil {
+		return nil
+	}
+	lock, err := c.fs.OpenFile(c.path+lockSuffix, os.O_RDWR|os.O_CREATE, 0666)
+	if err != nil {
+		return err
+	}
+
+	// billy only has a blocking lock, so it is taken in the background
+	// and released as soon as it is taken if the wait timed out
+	locked := make(chan error, 1)
+	go func() {
+		locked <- lock.Lock()
+	}()
+	select {
+	case err := <-locked:
+		if err != nil {
+			lock.Close()
+			return err
+		}
+	case <-time.After(lockTimeout):
+		go func() {
+			if err := <-locked; err == nil {
+				lock.Unlock()
+			}
+			lock.Close()
+		}()
+		return ErrCacheLocked
+	}
+	c.lock = lock
+	c.backedUp = false
+	return nil
+}
+
+// read locks the cache then returns its content, a missing
+// cache file is read as empty
+func (c *cacheFile) read() ([]byte, error) {
+	if err := c.acquire(); err != nil {
+		return nil, err
+	}
+	return readFile(c.fs, c.path)
+}
+
+// write replaces the cache with raw, the cache as it was when opened
+// is first copied to the backup file
+func (c *cacheFile) write(raw []byte) error {
+	if err := c.acquire(); err != nil {
+		return err
+	}
+	if !c.backedUp {
+		prev, err := readFile(c.fs, c.path)
+		if err != nil {
+			return err
+		}
+		if len(prev) > 0 {
+			if err := writeFileAtomic(c.fs, c.backupPath(), prev); err != nil {
+				return err
+			}
+		}
+		c.backedUp = true
+	}
+	return writeFileAtomic(c.fs, c.path, raw)
+}
+
+// backupVersion keeps a copy of the cache from before it was migrated
+// from version, an existing backup of that version is not replaced
+func (c *cacheFile) backupVersion(raw []byte, version string) error {
+	name := fmt.Sprintf("%s.v%s%s", c.path, version, backupSuffix)
+	if _, err := c.fs.Stat(name); err == nil {
+		return nil
+	}
+	return writeFileAtomic(c.fs, name, raw)
+}
+
+// close releases the lock on the cache
+func (c *cacheFile) close() error {
+	if c.lock == nil {
+		return nil
+	}
+	lock := c.lock
+	c.lock = nil
+	if err := lock.Unlock(); err != nil {
+		lock.Close()
+		return err
+	}
+	return lock.Close()
+}
+
+func readFile(fs billy.Filesystem, name string) ([]byte, error) {
+	f, err := fs.Open(name)
+	if os.IsNotExist(err) {
+		return []byte{}, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ioutil.ReadAll(f)
+}
+
+// writeFileAtomic writes raw to a temp file in the same directory
+// as name then renames it over name. The temp file is named after name
+// as it is only written while the lock on the cache is held
+func writeFileAtomic(fs billy.Filesystem, name string, raw []byte) error {
+	tmpName := name + tempSuffix
+	tmp, err := fs.OpenFile(tmpName, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(raw); err != nil {
+		tmp.Close()
+		fs.Remove(tmpName)
+		return err
+	}
+	if syncer, ok := tmp.(interface{ Sync() error }); ok {
+		if err := syncer.Sync(); err != nil {
+			tmp.Close()
+			fs.Remove(tmpName)
+			return err
+		}
+	}
+	if err := tmp.Close(); err != nil {
+		fs.Remove(tmpName)
+		return err
+	}
+	return fs.Rename(tmpName, name)
+}
@@ -0,0 +1,158 @@
+package storage
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	billy "gopkg.in/src-d/go-billy.v4"
+	"gopkg.in/src-d/go-billy.v4/memfs"
+	"gopkg.in/src-d/go-billy.v4/osfs"
+)
+
+var (
+	testCachePath = "/test/.hermes/cache.json"
+)
+
+type FileStorageSuite struct {
+	suite.Suite
+	fs billy.Filesystem
+}
+
+func (s *FileStorageSuite) SetupTest() {
+	s.fs = memfs.New()
+	s.Nil(s.fs.MkdirAll("/test/.hermes/", 0751), "Setup should create the config dir")
+}
+
+func (s *FileStorageSuite) readFile(name string) string {
+	f, err := s.fs.Open(name)
+	s.Nil(err, "File %s should exist", name)
+	raw, err := ioutil.ReadAll(f)
+	s.Nil(err, "File %s should be read", name)
+	return string(raw)
+}
+
+func (s *FileStorageSuite) TestOpenMissingCache() {
+	store := NewFileStorage(s.fs, testCachePath)
+	s.Nil(store.Open(), "A missing cache should open as empty")
+	s.Empty(store.ListRemotes(), "There should be no remotes")
+	s.Nil(store.Close())
+}
+
+func (s *FileStorageSuite) TestSaveThenOpen() {
+	store := NewFileStorage(s.fs, testCachePath)
+	s.Nil(store.Open())
+	s.Nil(store.AddRemote("https://github.com", "github.com", "github", "https"))
+	s.Nil(store.AddRepository(&Repository{
+		Name: "github.com/TheHipbot/hermes",
+		Path: "/repos/github.com/TheHipbot/hermes",
+	}))
+	s.Nil(store.Save(), "Save should succeed")
+	s.Nil(store.Close())
+
+	store = NewFileStorage(s.fs, testCachePath)
+	s.Nil(store.Open())
+	s.Len(store.SearchRepositories("hermes"), 1, "Saved repo should be in the cache")
+	s.Nil(store.Close())
+
+	files, err := s.fs.ReadDir("/test/.hermes/")
+	s.Nil(err)
+	for _, f := range files {
+		s.NotContains(f.Name(), tempSuffix, "No temp files should be left behind")
+	}
+}
+
+func (s *FileStorageSuite) TestSaveOnOSFilesystem() {
+	dir, err := ioutil.TempDir("", "hermes-cache")
+	s.Nil(err)
+	defer os.RemoveAll(dir)
+
+	cachePath := filepath.Join(dir, "cache.json")
+	store := NewFileStorage(osfs.New(""), cachePath)
+	s.Nil(store.Open())
+	s.Nil(store.AddRepository(&Repository{
+		Name: "github.com/TheHipbot/hermes",
+	}))
+	s.Nil(store.Save(), "Save should succeed on the os filesystem hermes uses")
+	s.Nil(store.Close())
+
+	store = NewFileStorage(osfs.New(""), cachePath)
+	s.Nil(store.Open())
+	defer store.Close()
+	s.Len(store.SearchRepositories("hermes"), 1, "Saved repo should be in the cache")
+}
+
+func (s *FileStorageSuite) TestLockTimeout() {
+	dir, err := ioutil.TempDir("", "hermes-cache")
+	s.Nil(err)
+	defer os.RemoveAll(dir)
+	defer func(timeout time.Duration) {
+		lockTimeout = timeout
+	}(lockTimeout)
+	lockTimeout = 50 * time.Millisecond
+
+	cachePath := filepath.Join(dir, "cache.json")
+	store := NewFileStorage(osfs.New(""), cachePath)
+	s.Nil(store.Open())
+	s.Equal(ErrCacheLocked, NewFileStorage(osfs.New(""), cachePath).Open(),
+		"Opening a cache another process has open should time out")
+
+	s.Nil(store.Close())
+	store = NewFileStorage(osfs.New(""), cachePath)
+	s.Nil(store.Open(), "The lock should be released after timing out")
+	s.Nil(store.Close())
+}
+
+func (s *FileStorageSuite) TestSaveBacksUpPreviousCache() {
+	store := NewFileStorage(s.fs, testCachePath)
+	s.Nil(store.Open())
+	s.Nil(store.AddRemote("https://github.com", "github.com", "github", "https"))
+	s.Nil(store.Save())
+	s.Nil(store.Close())
+	firstSave := s.readFile(testCachePath)
+
+	s.Nil(store.Open())
+	s.Nil(store.AddRemote("https://gitlab.com", "gitlab.com", "gitlab", "https"))
+	s.Nil(store.Save())
+	s.Nil(store.AddRemote("https://gopkg.in", "gopkg.in", "github", "https"))
+	s.Nil(store.Save())
+	s.Nil(store.Close())
+
+	s.Equal(firstSave, s.readFile(testCachePath+backupSuffix), "Backup should hold the cache as it was when opened")
+	s.NotEqual(firstSave, s.readFile(testCachePath), "Cache should hold the new remotes")
+}
+
+func (s *FileStorageSuite) TestOpenCorruptCache() {
+	corrupt := `{"version": "0.0.1", "remotes": {`
+	f, err := s.fs.Create(testCachePath)
+	s.Nil(err)
+	f.Write([]byte(corrupt))
+	f.Close()
+
+	store := NewFileStorage(s.fs, testCachePath)
+	err = store.Open()
+	s.True(errors.Is(err, ErrCorruptCache), "Open should report the corrupt cache")
+	s.Contains(err.Error(), testCachePath+backupSuffix, "Error should point to the backup")
+	s.Equal(ErrCorruptCache, store.Save(), "A corrupt cache should never be saved")
+	s.Nil(store.Close())
+	s.Equal(corrupt, s.readFile(testCachePath), "Corrupt cache should be left untouched")
+}
+
+func (s *FileStorageSuite) TestLockFile() {
+	store := NewFileStorage(s.fs, testCachePath)
+	s.Nil(store.Open())
+	impl := store.(*storage)
+	s.NotNil(impl.file.lock, "Lock should be held after open")
+	_, err := s.fs.Stat(testCachePath + lockSuffix)
+	s.Nil(err, "Lock file should be created next to the cache")
+	s.Nil(store.Close())
+	s.Nil(impl.file.lock, "Lock should be released on close")
+}
+
+func TestFileStorageSuite(t *testing.T) {
+	suite.Run(t, new(FileStorageSuite))
+}
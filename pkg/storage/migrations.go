@@ -0,0 +1,137 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	// initialFormatVersion is assumed for caches written
+	// without a version
+	initialFormatVersion = "0.0.1"
+)
+
+var (
+	// ErrCacheTooNew is returned when saving a cache written by a newer
+	// version of hermes than this one understands
+	ErrCacheTooNew = errors.New("cache was written by a newer version of hermes, upgrade hermes to make changes to it")
+
+	// ErrUnknownCacheVersion is returned when there is no migration
+	// from the version of the cache
+	ErrUnknownCacheVersion = errors.New("cache version unknown")
+
+	// migrations upgrade the cache one format version at a time, in order
+	migrations = []migration{
+		{
+			from:    "0.0.1",
+			to:      "0.1.0",
+			upgrade: normalizeRemotes,
+		},
+	}
+)
+
+// rawCache is the cache as decoded from json without a schema, so
+// migrations can change its shape
+type rawCache map[string]interface{}
+
+// migration upgrades a raw cache from one format version to the next
+type migration struct {
+	from    string
+	to      string
+	upgrade func(cache rawCache) error
+}
+
+// migrate upgrades the raw cache from version to the current
+// format version
+func migrate(raw []byte, version string) ([]byte, error) {
+	cache := rawCache{}
+	if err := json.Unmarshal(raw, &cache); err != nil {
+		return nil, err
+	}
+	originalVersion := version
+
+	for _, m := range migrations {
+		if compareVersions(version, cacheFormatVersion) >= 0 {
+			break
+		}
+		if m.from != version {
+			continue
+		}
+		if err := m.upgrade(cache); err != nil {
+			return nil, fmt.Errorf("error migrating cache from %s to %s: %s", m.from, m.to, err)
+		}
+		version = m.to
+		cache["version"] = version
+	}
+
+	if compareVersions(version, cacheFormatVersion) < 0 {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownCacheVersion, originalVersion)
+	}
+
+	return json.Marshal(cache)
+}
+
+// compareVersions compares two dotted numeric versions, returning -1, 0
+// or 1 if a is older, equal to or newer than b
+func compareVersions(a, b string) int {
+	as := strings.Split(a, ".")
+	bs := strings.Split(b, ".")
+	for i := 0; i < len(as) || i < len(bs); i++ {
+		var x, y int
+		if i < len(as) {
+			x, _ = strconv.Atoi(as[i])
+		}
+		if i < len(bs) {
+			y, _ = strconv.Atoi(bs[i])
+		}
+		if x < y {
+			return -1
+		} else if x > y {
+			return 1
+		}
+	}
+	return 0
+}
+
+// normalizeRemotes upgrades 0.0.1 to 0.1.0, every remote gets a repos and
+// meta object, repos missing a name take it from their key and repos
+// without a name at all are dropped
+func normalizeRemotes(cache rawCache) error {
+	remotes, ok := cache["remotes"].(map[string]interface{})
+	if !ok {
+		cache["remotes"] = map[string]interface{}{}
+		return nil
+	}
+
+	for remoteName, r := range remotes {
+		remote, ok := r.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("remote %s is not an object", remoteName)
+		}
+		if name, _ := remote["name"].(string); name == "" {
+			remote["name"] = remoteName
+		}
+		if _, ok := remote["meta"].(map[string]interface{}); !ok {
+			remote["meta"] = map[string]interface{}{}
+		}
+		repos, ok := remote["repos"].(map[string]interface{})
+		if !ok {
+			remote["repos"] = map[string]interface{}{}
+			continue
+		}
+		for key, rp := range repos {
+			repo, ok := rp.(map[string]interface{})
+			if !ok || key == "" {
+				delete(repos, key)
+				continue
+			}
+			if name, _ := repo["name"].(string); name == "" {
+				repo["name"] = key
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,159 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+
+	"gopkg.in/src-d/go-billy.v4/memfs"
+)
+
+var (
+	testCacheV001 = `{
+		"version": "0.0.1",
+		"remotes": {
+			"github.com": {
+				"name": "github.com",
+				"url":  "https://github.com",
+				"meta": null,
+				"repos": {
+					"github.com/TheHipbot/hermes": {
+						"repo_path": "/repos/github.com/TheHipbot/hermes"
+					},
+					"github.com/TheHipbot/dotfiles": {
+						"name": "github.com/TheHipbot/dotfiles",
+						"repo_path": "/repos/github.com/TheHipbot/dotfiles"
+					}
+				}
+			},
+			"gitlab.com": {
+				"url":  "https://gitlab.com",
+				"repos": null
+			}
+		}
+	}`
+)
+
+func (s *StorageSuite) writeTestCache(content string) {
+	_, err := testStorer.Write([]byte(content))
+	s.Nil(err, "Test cache should be written")
+}
+
+func (s *StorageSuite) TestMigrationsAreOrdered() {
+	version := initialFormatVersion
+	for _, m := range migrations {
+		s.Equal(version, m.from, "Each migration should start from the version the previous one ended at")
+		s.Equal(1, compareVersions(m.to, m.from), "Each migration should upgrade to a newer version")
+		version = m.to
+	}
+	s.Equal(cacheFormatVersion, version, "Migrations should end at the current format version")
+}
+
+func (s *StorageSuite) TestMigrateNormalizeRemotes() {
+	cache := rawCache{}
+	s.Nil(json.Unmarshal([]byte(testCacheV001), &cache))
+	s.Nil(normalizeRemotes(cache))
+
+	remotes := cache["remotes"].(map[string]interface{})
+	github := remotes["github.com"].(map[string]interface{})
+	s.Equal(map[string]interface{}{}, github["meta"], "Null meta should be replaced with an object")
+	repos := github["repos"].(map[string]interface{})
+	hermes := repos["github.com/TheHipbot/hermes"].(map[string]interface{})
+	s.Equal("github.com/TheHipbot/hermes", hermes["name"], "Missing repo name should be set from its key")
+
+	gitlab := remotes["gitlab.com"].(map[string]interface{})
+	s.Equal("gitlab.com", gitlab["name"], "Missing remote name should be set from its key")
+	s.Equal(map[string]interface{}{}, gitlab["repos"], "Null repos should be replaced with an object")
+}
+
+func (s *StorageSuite) TestMigrateNormalizeRemotesInvalidRemote() {
+	cache := rawCache{
+		"remotes": map[string]interface{}{
+			"github.com": "github",
+		},
+	}
+	s.NotNil(normalizeRemotes(cache), "A remote which is not an object cannot be migrated")
+}
+
+func (s *StorageSuite) TestOpenMigratesOldCache() {
+	s.writeTestCache(testCacheV001)
+	cache := &storage{
+		storer: testStorer,
+	}
+	s.Nil(cache.Open(), "Old cache should be migrated without error")
+	s.Equal(cacheFormatVersion, cache.Version, "Cache should be at the current version")
+	s.Len(cache.SearchRepositories("thehipbot"), 2, "Migrated cache should keep its repos")
+	s.NotNil(cache.Remotes["gitlab.com"].Repos, "Migrated remotes should have repos")
+	cache.AddRepository(&Repository{
+		Name: "gitlab.com/TheHipbot/hermes",
+	})
+	s.Len(cache.SearchRepositories("gitlab.com"), 1, "Repos should be addable to migrated remotes")
+
+	s.Nil(cache.Save(), "Migrated cache should save")
+	var saved storage
+	raw, err := ioutil.ReadAll(testStorer)
+	s.Nil(err)
+	s.Nil(json.Unmarshal(raw, &saved))
+	s.Equal(cacheFormatVersion, saved.Version, "Saved cache should be at the current version")
+}
+
+func (s *StorageSuite) TestOpenUnversionedCache() {
+	s.writeTestCache(`{"remotes": {}}`)
+	cache := &storage{
+		storer: testStorer,
+	}
+	s.Nil(cache.Open(), "Unversioned cache should be migrated from the initial version")
+	s.Equal(cacheFormatVersion, cache.Version)
+}
+
+func (s *StorageSuite) TestOpenNewerCache() {
+	newer := `{"version": "99.0.0", "remotes": {"github.com": {"name": "github.com", "repos": {"github.com/TheHipbot/hermes": {"name": "github.com/TheHipbot/hermes"}}}}}`
+	s.writeTestCache(newer)
+	cache := &storage{
+		storer: testStorer,
+	}
+	s.Nil(cache.Open(), "Newer cache should still be readable")
+	s.Len(cache.SearchRepositories("hermes"), 1, "Newer cache should be searchable")
+	s.Equal(ErrCacheTooNew, cache.Save(), "Newer cache should never be written")
+
+	testStorer.Seek(0, 0)
+	raw, err := ioutil.ReadAll(testStorer)
+	s.Nil(err)
+	s.Equal(newer, string(raw), "Newer cache should be left untouched")
+}
+
+func (s *StorageSuite) TestOpenUnknownVersion() {
+	s.writeTestCache(`{"version": "0.0.0", "remotes": {}}`)
+	cache := &storage{
+		storer: testStorer,
+	}
+	err := cache.Open()
+	s.True(errors.Is(err, ErrUnknownCacheVersion), "Cache without a migration path should error")
+	s.NotNil(cache.Save(), "Cache which could not be migrated should not be written")
+}
+
+func (s *StorageSuite) TestMigrationBackup() {
+	fs := memfs.New()
+	f, err := fs.Create("/test/cache.json")
+	s.Nil(err)
+	f.Write([]byte(testCacheV001))
+	f.Close()
+
+	cache := NewFileStorage(fs, "/test/cache.json")
+	s.Nil(cache.Open())
+	s.Nil(cache.Close())
+
+	f, err = fs.Open("/test/cache.json.v0.0.1.bak")
+	s.Nil(err, "Cache should be backed up before it is migrated")
+	raw, err := ioutil.ReadAll(f)
+	s.Nil(err)
+	s.Equal(testCacheV001, string(raw), "Backup should hold the cache from before the migration")
+}
+
+func (s *StorageSuite) TestCompareVersions() {
+	s.Equal(0, compareVersions("0.1.0", "0.1.0"))
+	s.Equal(-1, compareVersions("0.0.1", "0.1.0"))
+	s.Equal(1, compareVersions("0.10.0", "0.9.0"))
+	s.Equal(1, compareVersions("1.0", "0.9.9"))
+	s.Equal(0, compareVersions("1.0", "1.0.0"))
+}
@@ -2,10 +2,13 @@ package storage
 
 // Remote is a parent node in the cache tree
 type Remote struct {
-	Name     string                 `json:"name"`
-	URL      string                 `json:"url"`
-	Protocol string                 `json:"protocol"`
-	Type     string                 `json:"type"`
-	Meta     map[string]string      `json:"meta"`
-	Repos    map[string]*Repository `json:"repos"`
+	Name     string            `json:"name"`
+	URL      string            `json:"url"`
+	Protocol string            `json:"protocol"`
+	Type     string            `json:"type"`
+	Meta     map[string]string `json:"meta"`
+	// GitConfig is set in the local git config of
+	// the remote's repos, such as user.email
+	GitConfig map[string]string      `json:"git_config,omitempty"`
+	Repos     map[string]*Repository `json:"repos"`
 }
@@ -1,10 +1,70 @@
 package storage
 
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
 // Repository stores a repo and its location on the filesystem
-// for use in autocomplete
+// for use in autocomplete, along with details about the repo
+// from its remote
 type Repository struct {
-	Name     string `json:"name"`
-	Path     string `json:"repo_path"`
-	CloneURL string `json:"clone_url"`
-	SSHURL   string `json:"ssh_url"`
+	Name          string     `json:"name"`
+	Path          string     `json:"repo_path"`
+	CloneURL      string     `json:"clone_url"`
+	SSHURL        string     `json:"ssh_url"`
+	WebURL        string     `json:"web_url"`
+	Description   string     `json:"description,omitempty"`
+	DefaultBranch string     `json:"default_branch,omitempty"`
+	Language      string     `json:"language,omitempty"`
+	Topics        []string   `json:"topics,omitempty"`
+	Visibility    string     `json:"visibility,omitempty"`
+	Archived      bool       `json:"archived,omitempty"`
+	Stars         int        `json:"stars,omitempty"`
+	LastActivity  *time.Time `json:"last_activity,omitempty"`
+	Tags          []string   `json:"tags,omitempty"`
+}
+
+// HasTag reports whether the repo is tagged with tag
+func (r *Repository) HasTag(tag string) bool {
+	tag = strings.ToLower(tag)
+	for _, t := range r.Tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// AddTags tags the repo, tags are stored lowercase
+// and sorted without duplicates
+func (r *Repository) AddTags(tags ...string) {
+	for _, tag := range tags {
+		if !r.HasTag(tag) {
+			r.Tags = append(r.Tags, strings.ToLower(tag))
+		}
+	}
+	sort.Strings(r.Tags)
+}
+
+// RemoveTags removes the tags from the repo
+func (r *Repository) RemoveTags(tags ...string) {
+	kept := r.Tags[:0]
+	for _, t := range r.Tags {
+		removed := false
+		for _, tag := range tags {
+			if t == strings.ToLower(tag) {
+				removed = true
+				break
+			}
+		}
+		if !removed {
+			kept = append(kept, t)
+		}
+	}
+	r.Tags = kept
+	if len(r.Tags) == 0 {
+		r.Tags = nil
+	}
 }
@@ -0,0 +1,104 @@
+package storage
+
+import (
+	"strconv"
+	"strings"
+)
+
+// searchQualifiers match a repo against the value of a qualified search
+// term such as lang:go, the value is lowercased before it is passed in
+var searchQualifiers = map[string]func(repo *Repository, value string) bool{
+	"lang":        matchLanguage,
+	"language":    matchLanguage,
+	"topic":       matchTopic,
+	"desc":        matchDescription,
+	"description": matchDescription,
+	"visibility":  matchVisibility,
+	"archived":    matchArchived,
+	"tag":         matchTag,
+	"remote":      matchRemote,
+}
+
+// repoQuery is a parsed search, every term must match for a repo to
+// be a result. Terms without a known qualifier are matched against
+// the repo name
+type repoQuery struct {
+	names   []string
+	filters []func(repo *Repository) bool
+}
+
+// parseQuery splits needle into whitespace separated terms
+func parseQuery(needle string) repoQuery {
+	q := repoQuery{}
+	for _, term := range strings.Fields(strings.ToLower(needle)) {
+		if i := strings.Index(term, ":"); i > 0 {
+			if match, ok := searchQualifiers[term[:i]]; ok {
+				value := term[i+1:]
+				q.filters = append(q.filters, func(repo *Repository) bool {
+					return match(repo, value)
+				})
+				continue
+			}
+		}
+		q.names = append(q.names, term)
+	}
+	return q
+}
+
+// matchesName reports whether name contains every unqualified term
+func (q repoQuery) matchesName(name string) bool {
+	lowerName := strings.ToLower(name)
+	for _, n := range q.names {
+		if !strings.Contains(lowerName, n) {
+			return false
+		}
+	}
+	return true
+}
+
+// matches reports whether the repo stored under name matches every term
+func (q repoQuery) matches(name string, repo *Repository) bool {
+	if !q.matchesName(name) {
+		return false
+	}
+	for _, f := range q.filters {
+		if !f(repo) {
+			return false
+		}
+	}
+	return true
+}
+
+func matchLanguage(repo *Repository, value string) bool {
+	return strings.ToLower(repo.Language) == value
+}
+
+func matchTopic(repo *Repository, value string) bool {
+	for _, t := range repo.Topics {
+		if strings.ToLower(t) == value {
+			return true
+		}
+	}
+	return false
+}
+
+func matchDescription(repo *Repository, value string) bool {
+	return strings.Contains(strings.ToLower(repo.Description), value)
+}
+
+func matchVisibility(repo *Repository, value string) bool {
+	return strings.ToLower(repo.Visibility) == value
+}
+
+func matchArchived(repo *Repository, value string) bool {
+	archived, err := strconv.ParseBool(value)
+	return err == nil && repo.Archived == archived
+}
+
+func matchTag(repo *Repository, value string) bool {
+	return repo.HasTag(value)
+}
+
+func matchRemote(repo *Repository, value string) bool {
+	return strings.ToLower(strings.Split(repo.Name, "/")[0]) == value
+}
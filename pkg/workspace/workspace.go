@@ -0,0 +1,226 @@
+package workspace
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"sort"
+
+	billy "gopkg.in/src-d/go-billy.v4"
+	"gopkg.in/yaml.v2"
+)
+
+var (
+	// ErrWorkspaceExists is returned when creating a workspace
+	// with the name of an existing one
+	ErrWorkspaceExists = errors.New("workspace already exists")
+
+	// ErrWorkspaceNotFound is returned when there is no
+	// workspace with the given name
+	ErrWorkspaceNotFound = errors.New("workspace not found")
+
+	// ErrInvalidWorkspace is returned when an imported
+	// workspace has no name
+	ErrInvalidWorkspace = errors.New("workspace must have a name")
+)
+
+// Workspace is a named set of repos which are cloned and updated
+// together. Members are listed by repo name or by a search query
+// which is run against the cache whenever the workspace is used
+type Workspace struct {
+	Name    string   `yaml:"name"`
+	Repos   []string `yaml:"repos,omitempty"`
+	Queries []string `yaml:"queries,omitempty"`
+}
+
+// AddRepos adds repos to the workspace by name
+func (w *Workspace) AddRepos(names ...string) {
+	w.Repos = addUnique(w.Repos, names)
+}
+
+// AddQueries adds search queries to the workspace
+func (w *Workspace) AddQueries(queries ...string) {
+	w.Queries = addUnique(w.Queries, queries)
+}
+
+// Remove removes the entries from the workspace's repos and queries,
+// returning how many were removed
+func (w *Workspace) Remove(entries ...string) int {
+	var removed int
+	w.Repos, removed = removeAll(w.Repos, entries)
+	var removedQueries int
+	w.Queries, removedQueries = removeAll(w.Queries, entries)
+	return removed + removedQueries
+}
+
+// Export writes the workspace as yaml
+func (w *Workspace) Export(out io.Writer) error {
+	raw, err := yaml.Marshal(w)
+	if err != nil {
+		return err
+	}
+	_, err = out.Write(raw)
+	return err
+}
+
+// Import reads a workspace written by Export
+func Import(in io.Reader) (*Workspace, error) {
+	raw, err := ioutil.ReadAll(in)
+	if err != nil {
+		return nil, err
+	}
+	w := &Workspace{}
+	if err := yaml.UnmarshalStrict(raw, w); err != nil {
+		return nil, err
+	}
+	if w.Name == "" {
+		return nil, ErrInvalidWorkspace
+	}
+	w.Repos = addUnique(nil, w.Repos)
+	w.Queries = addUnique(nil, w.Queries)
+	return w, nil
+}
+
+// Store persists workspaces to a yaml file
+type Store struct {
+	fs         billy.Filesystem
+	path       string
+	Workspaces map[string]*Workspace `yaml:"workspaces"`
+}
+
+// NewStore creates a Store persisted to the file at path on fs
+func NewStore(fs billy.Filesystem, path string) *Store {
+	return &Store{
+		fs:         fs,
+		path:       path,
+		Workspaces: map[string]*Workspace{},
+	}
+}
+
+// Load reads the workspaces from the file, a missing
+// file is loaded as no workspaces
+func (s *Store) Load() error {
+	s.Workspaces = map[string]*Workspace{}
+	f, err := s.fs.Open(s.path)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	raw, err := ioutil.ReadAll(f)
+	if err != nil {
+		return err
+	}
+	if err := yaml.Unmarshal(raw, s); err != nil {
+		return err
+	}
+	if s.Workspaces == nil {
+		s.Workspaces = map[string]*Workspace{}
+	}
+	for name, w := range s.Workspaces {
+		w.Name = name
+	}
+	return nil
+}
+
+// Save writes the workspaces to the file
+func (s *Store) Save() error {
+	raw, err := yaml.Marshal(s)
+	if err != nil {
+		return err
+	}
+	f, err := s.fs.Create(s.path)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(raw); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Get returns the workspace with the given name
+func (s *Store) Get(name string) (*Workspace, error) {
+	if w, ok := s.Workspaces[name]; ok {
+		return w, nil
+	}
+	return nil, ErrWorkspaceNotFound
+}
+
+// Create adds an empty workspace with the given name
+func (s *Store) Create(name string) (*Workspace, error) {
+	if _, ok := s.Workspaces[name]; ok {
+		return nil, ErrWorkspaceExists
+	}
+	w := &Workspace{
+		Name: name,
+	}
+	s.Workspaces[name] = w
+	return w, nil
+}
+
+// Put adds the workspace, replacing any with the same name
+func (s *Store) Put(w *Workspace) {
+	s.Workspaces[w.Name] = w
+}
+
+// Delete removes the workspace with the given name
+func (s *Store) Delete(name string) error {
+	if _, ok := s.Workspaces[name]; !ok {
+		return ErrWorkspaceNotFound
+	}
+	delete(s.Workspaces, name)
+	return nil
+}
+
+// List returns the workspaces sorted by name
+func (s *Store) List() []*Workspace {
+	results := make([]*Workspace, 0, len(s.Workspaces))
+	for _, w := range s.Workspaces {
+		results = append(results, w)
+	}
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Name < results[j].Name
+	})
+	return results
+}
+
+func addUnique(list, entries []string) []string {
+	for _, e := range entries {
+		found := false
+		for _, l := range list {
+			if l == e {
+				found = true
+				break
+			}
+		}
+		if !found && e != "" {
+			list = append(list, e)
+		}
+	}
+	return list
+}
+
+func removeAll(list, entries []string) ([]string, int) {
+	kept := []string{}
+	for _, l := range list {
+		removed := false
+		for _, e := range entries {
+			if l == e {
+				removed = true
+				break
+			}
+		}
+		if !removed {
+			kept = append(kept, l)
+		}
+	}
+	if len(kept) == 0 {
+		kept = nil
+	}
+	return kept, len(list) - len(kept)
+}
@@ -0,0 +1,81 @@
+package workspace
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	"gopkg.in/src-d/go-billy.v4/memfs"
+)
+
+type WorkspaceSuite struct {
+	suite.Suite
+	store *Store
+}
+
+func (s *WorkspaceSuite) SetupTest() {
+	s.store = NewStore(memfs.New(), "/workspaces.yml")
+}
+
+func (s *WorkspaceSuite) TestLoadMissingFile() {
+	s.Nil(s.store.Load(), "A missing file should load as no workspaces")
+	s.Empty(s.store.List())
+}
+
+func (s *WorkspaceSuite) TestCreateSaveLoad() {
+	w, err := s.store.Create("payments")
+	s.Nil(err)
+	w.AddRepos("github.com/payments/api", "github.com/payments/api", "github.com/payments/web")
+	w.AddQueries("tag:payments")
+	_, err = s.store.Create("payments")
+	s.Equal(ErrWorkspaceExists, err)
+	_, err = s.store.Create("infra")
+	s.Nil(err)
+	s.Nil(s.store.Save())
+
+	s.Nil(s.store.Load())
+	list := s.store.List()
+	s.Len(list, 2)
+	s.Equal("infra", list[0].Name, "Workspaces should be sorted by name")
+	s.Equal("payments", list[1].Name)
+	s.Equal([]string{"github.com/payments/api", "github.com/payments/web"}, list[1].Repos, "Repos should not be duplicated")
+	s.Equal([]string{"tag:payments"}, list[1].Queries)
+}
+
+func (s *WorkspaceSuite) TestRemoveAndDelete() {
+	w, _ := s.store.Create("payments")
+	w.AddRepos("github.com/payments/api", "github.com/payments/web")
+	w.AddQueries("tag:payments")
+	s.Equal(2, w.Remove("github.com/payments/api", "tag:payments", "github.com/payments/ledger"))
+	s.Equal([]string{"github.com/payments/web"}, w.Repos)
+	s.Nil(w.Queries)
+
+	s.Nil(s.store.Delete("payments"))
+	s.Equal(ErrWorkspaceNotFound, s.store.Delete("payments"))
+	_, err := s.store.Get("payments")
+	s.Equal(ErrWorkspaceNotFound, err)
+}
+
+func (s *WorkspaceSuite) TestExportImport() {
+	w := &Workspace{
+		Name:    "payments",
+		Repos:   []string{"github.com/payments/api"},
+		Queries: []string{"tag:payments"},
+	}
+	buf := &bytes.Buffer{}
+	s.Nil(w.Export(buf))
+
+	imported, err := Import(buf)
+	s.Nil(err)
+	s.Equal(w, imported, "Imported workspace should match the export")
+
+	_, err = Import(strings.NewReader("repos:\n- github.com/payments/api\n"))
+	s.Equal(ErrInvalidWorkspace, err, "A workspace without a name should not be imported")
+	_, err = Import(strings.NewReader("name: payments\nmembers: []\n"))
+	s.NotNil(err, "Unknown fields should not be imported")
+}
+
+func TestWorkspaceSuite(t *testing.T) {
+	suite.Run(t, new(WorkspaceSuite))
+}